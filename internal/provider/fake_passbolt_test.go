@@ -0,0 +1,274 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/ProtonMail/gopenpgp/v2/helper"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/passbolt/go-passbolt/api"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"terraform-provider-passbolt/tools"
+	"testing"
+)
+
+const (
+	testPassphrase     = "correct horse battery staple"
+	testResourceTypeID = "a28a04cd-6f53-518a-967c-9963bf9cec51"
+)
+
+var (
+	testKeyOnce      sync.Once
+	testPrivateKey   string
+	testPublicKey    string
+	errTestKeyCreate error
+)
+
+// testUserKey returns a locked private key and its public key, generated
+// once for all tests.
+func testUserKey(t *testing.T) (string, string) {
+	t.Helper()
+	testKeyOnce.Do(func() {
+		var key, locked *crypto.Key
+		key, errTestKeyCreate = crypto.GenerateKey("Terraform", "terraform@example.com", "x25519", 0)
+		if errTestKeyCreate != nil {
+			return
+		}
+		locked, errTestKeyCreate = key.Lock([]byte(testPassphrase))
+		if errTestKeyCreate != nil {
+			return
+		}
+		testPrivateKey, errTestKeyCreate = locked.Armor()
+		if errTestKeyCreate != nil {
+			return
+		}
+		testPublicKey, errTestKeyCreate = key.GetArmoredPublicKey()
+	})
+	if errTestKeyCreate != nil {
+		t.Fatal(errTestKeyCreate)
+	}
+	return testPrivateKey, testPublicKey
+}
+
+// fakeRequest is a request received by fakePassbolt.
+type fakeRequest struct {
+	Method string
+	Path   string
+	Body   []byte
+}
+
+// fakePassbolt serves the Passbolt API from handlers registered per method
+// and path, and records every request it receives.
+type fakePassbolt struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]http.HandlerFunc
+	requests []fakeRequest
+}
+
+func newFakePassbolt(t *testing.T) *fakePassbolt {
+	f := &fakePassbolt{t: t, handlers: map[string]http.HandlerFunc{}}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakePassbolt) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	route := r.Method + " " + r.URL.Path
+
+	f.mu.Lock()
+	f.requests = append(f.requests, fakeRequest{Method: r.Method, Path: r.URL.Path, Body: body})
+	handler, ok := f.handlers[route]
+	f.mu.Unlock()
+
+	if !ok {
+		f.t.Errorf("unexpected request %s", route)
+		writeAPIError(w, http.StatusNotFound, "The route does not exist.")
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	handler(w, r)
+}
+
+// handle answers requests to route, "<method> <path>", with body.
+func (f *fakePassbolt) handle(route string, body interface{}) {
+	f.handleFunc(route, func(w http.ResponseWriter, _ *http.Request) {
+		writeAPIResponse(w, body)
+	})
+}
+
+// handleNotFound answers requests to route with the error Passbolt returns
+// for missing objects.
+func (f *fakePassbolt) handleNotFound(route string) {
+	f.handleFunc(route, func(w http.ResponseWriter, _ *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "The object does not exist.")
+	})
+}
+
+func (f *fakePassbolt) handleFunc(route string, handler http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[route] = handler
+}
+
+// requested returns the requests received for route.
+func (f *fakePassbolt) requested(route string) []fakeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var requests []fakeRequest
+	for _, request := range f.requests {
+		if request.Method+" "+request.Path == route {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// client returns a provider client for the fake server, using the test user
+// key without logging in.
+func (f *fakePassbolt) client() *tools.PassboltClient {
+	privateKey, _ := testUserKey(f.t)
+	client, err := api.NewClient(f.server.Client(), "", f.server.URL, privateKey, testPassphrase)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return &tools.PassboltClient{Client: client, Url: f.server.URL, PrivateKey: privateKey, Password: testPassphrase}
+}
+
+// handlePassword serves what helper.ShareResource reads of a password: its
+// permissions, secret and resource type. Shares add no user, so no secret
+// is encrypted for them.
+func (f *fakePassbolt) handlePassword(resourceID string, permissions []api.Permission) {
+	_, publicKey := testUserKey(f.t)
+	secret, err := helper.EncryptMessageArmored(publicKey, `{"password":"secret"}`)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	f.handle("GET /permissions/resource/"+resourceID+".json", permissions)
+	f.handle("GET /secrets/resource/"+resourceID+".json", api.Secret{ResourceID: resourceID, Data: secret})
+	f.handle("GET /resources/"+resourceID+".json", api.Resource{ID: resourceID, ResourceTypeID: testResourceTypeID})
+	f.handle("GET /resource-types/"+testResourceTypeID+".json", api.ResourceType{
+		ID:         testResourceTypeID,
+		Slug:       "password-and-description",
+		Definition: json.RawMessage(`{"resource":{"type":"object"},"secret":{"type":"object"}}`),
+	})
+	f.handle("POST /share/simulate/resource/"+resourceID+".json", api.ResourceShareSimulationResult{})
+	f.handle("PUT /share/resource/"+resourceID+".json", nil)
+}
+
+// handleGroup serves a group with its memberships and accepts updates of
+// it. Secrets shared with the group need no encryption for new members.
+func (f *fakePassbolt) handleGroup(groupID, name string, memberships []api.GroupMembership) {
+	for i := range memberships {
+		memberships[i].GroupID = groupID
+		if memberships[i].User.Profile == nil {
+			memberships[i].User.Profile = &api.Profile{}
+		}
+	}
+	group := api.Group{ID: groupID, Name: name, GroupUsers: memberships}
+
+	f.handle("GET /groups/"+groupID+".json", group)
+	f.handle("GET /groups.json", []api.Group{group})
+	f.handle("PUT /groups/"+groupID+"/dry-run.json", api.UpdateGroupDryRunResult{})
+	f.handle("PUT /groups/"+groupID+".json", group)
+}
+
+// groupUpdate decodes the update of a group request.
+func groupUpdate(t *testing.T, request fakeRequest) api.GroupUpdate {
+	t.Helper()
+	var update api.GroupUpdate
+	if err := json.Unmarshal(request.Body, &update); err != nil {
+		t.Fatal(err)
+	}
+	return update
+}
+
+// sharedPermissions decodes the permission changes of a share request.
+func sharedPermissions(t *testing.T, request fakeRequest) []api.Permission {
+	t.Helper()
+	var share api.ResourceShareRequest
+	if err := json.Unmarshal(request.Body, &share); err != nil {
+		t.Fatal(err)
+	}
+	return share.Permissions
+}
+
+func writeAPIResponse(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"header": api.APIHeader{Status: "success", Code: http.StatusOK},
+		"body":   body,
+	})
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIErrorBody(w, status, message, nil)
+}
+
+func writeAPIErrorBody(w http.ResponseWriter, status int, message string, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"header": api.APIHeader{Status: "error", Code: status, Message: message},
+		"body":   body,
+	})
+}
+
+// nullTimeouts is the value of an unset timeouts block.
+func nullTimeouts() timeouts.Value {
+	return timeouts.Value{Object: types.ObjectNull(map[string]attr.Type{
+		"create": types.StringType,
+		"read":   types.StringType,
+		"update": types.StringType,
+		"delete": types.StringType,
+	})}
+}
+
+// resourceSchema returns the schema of r.
+func resourceSchema(t *testing.T, r resource.Resource) schema.Schema {
+	t.Helper()
+	var resp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+	return resp.Schema
+}
+
+// emptyState returns a state without value for r.
+func emptyState(t *testing.T, r resource.Resource) tfsdk.State {
+	s := resourceSchema(t, r)
+	return tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+}
+
+// stateOf returns the state of r holding model.
+func stateOf(t *testing.T, r resource.Resource, model interface{}) tfsdk.State {
+	t.Helper()
+	state := emptyState(t, r)
+	if diags := state.Set(context.Background(), model); diags.HasError() {
+		t.Fatal(diags)
+	}
+	return state
+}
+
+// planOf returns the plan of r holding model.
+func planOf(t *testing.T, r resource.Resource, model interface{}) tfsdk.Plan {
+	t.Helper()
+	state := stateOf(t, r, model)
+	return tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+}
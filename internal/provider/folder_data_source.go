@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
 	"terraform-provider-passbolt/tools"
 )
 
@@ -63,7 +64,7 @@ func (d *folderDataSource) Schema(_ context.Context, _ datasource.SchemaRequest,
 			},
 			"name": schema.StringAttribute{
 				Computed: true,
-			},			
+			},
 			"folder_parent_id": schema.StringAttribute{
 				Computed: true,
 			},
@@ -80,7 +81,11 @@ func (d *folderDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	folder, err := d.client.Client.GetFolder(d.client.Context, conf.ID.ValueString(),nil)
+	var folder *api.Folder
+	err := d.client.Do(ctx, func() (err error) {
+		folder, err = d.client.Client.GetFolder(ctx, conf.ID.ValueString(), nil)
+		return err
+	})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Read folder", "",
@@ -94,8 +99,6 @@ func (d *folderDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		FolderParentId: types.StringValue(folder.FolderParentID),
 	}
 
-
-
 	// Set state
 	diag := resp.State.Set(ctx, folderState)
 	resp.Diagnostics.Append(diag...)
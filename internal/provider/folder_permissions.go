@@ -0,0 +1,368 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/passbolt/go-passbolt/api"
+	"terraform-provider-passbolt/tools"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &folderPermissions{}
+	_ resource.ResourceWithConfigure   = &folderPermissions{}
+	_ resource.ResourceWithImportState = &folderPermissions{}
+	_ resource.ResourceWithModifyPlan  = &folderPermissions{}
+)
+
+// NewFolderPermissions is a helper function to simplify the provider implementation.
+func NewFolderPermissions() resource.Resource {
+	return &folderPermissions{}
+}
+
+// folderPermissions manages all permissions of a folder and optionally of
+// its contents.
+type folderPermissions struct {
+	client *tools.PassboltClient
+}
+
+type folderPermissionsModel struct {
+	ID                  types.String      `tfsdk:"id"`
+	FolderId            types.String      `tfsdk:"folder_id"`
+	Permissions         []permissionModel `tfsdk:"permissions"`
+	PropagateToChildren types.Bool        `tfsdk:"propagate_to_children"`
+	AffectedFolderIds   types.Set         `tfsdk:"affected_folder_ids"`
+	AffectedResourceIds types.Set         `tfsdk:"affected_resource_ids"`
+	Timeouts            timeouts.Value    `tfsdk:"timeouts"`
+}
+
+// folderDescendants are the subfolders and passwords below a folder, the
+// subfolders are ordered from the top down.
+type folderDescendants struct {
+	FolderIDs   []string
+	ResourceIDs []string
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *folderPermissions) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*tools.PassboltClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *passboltClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// Metadata returns the resource type name.
+func (r *folderPermissions) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_folder_permissions"
+}
+
+// Schema defines the schema for the resource.
+func (r *folderPermissions) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages all permissions of a folder. Destroying it leaves the permissions in place.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"folder_id": schema.StringAttribute{
+				Description: "ID of the folder.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"permissions": permissionsAttribute("folder"),
+			"propagate_to_children": schema.BoolAttribute{
+				Description: "Apply the permissions to all subfolders and passwords of the folder as well.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"affected_folder_ids": schema.SetAttribute{
+				Description: "Subfolders the permissions are propagated to.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"affected_resource_ids": schema.SetAttribute{
+				Description: "Passwords the permissions are propagated to.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// ModifyPlan lists the subfolders and passwords the permissions will be
+// propagated to, so that the plan shows every affected item and changes when
+// items are added to the folder.
+func (r *folderPermissions) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing is changed when the resource is destroyed.
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var folderID types.String
+	var propagate types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("folder_id"), &folderID)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("propagate_to_children"), &propagate)...)
+	if resp.Diagnostics.HasError() || folderID.IsUnknown() || propagate.IsUnknown() {
+		return
+	}
+
+	var descendants folderDescendants
+	if propagate.ValueBool() {
+		var err error
+		descendants, err = getFolderDescendants(ctx, r.client, folderID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to list folder contents",
+				"Could not list the contents of folder "+folderID.ValueString()+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("affected_folder_ids"), stringSet(descendants.FolderIDs))...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("affected_resource_ids"), stringSet(descendants.ResourceIDs))...)
+}
+
+// Create replaces the permissions of the folder by the planned ones.
+func (r *folderPermissions) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan folderPermissionsModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = plan.FolderId
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data. The contents of
+// the folder are compared during planning.
+func (r *folderPermissions) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state folderPermissionsModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	permissions, err := getFolderPermissions(ctx, r.client, state.FolderId.ValueString())
+	if tools.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read folder permissions",
+			"Could not read the permissions of folder "+state.FolderId.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	state.ID = state.FolderId
+	state.Permissions = permissionModels(permissions)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update replaces the permissions of the folder by the planned ones.
+func (r *folderPermissions) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan folderPermissionsModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = plan.FolderId
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete only removes the resource from the state, a folder cannot be left
+// without permissions.
+func (r *folderPermissions) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var folderID types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("folder_id"), &folderID)...)
+
+	tflog.Info(ctx, "Leaving the permissions of the folder in place", map[string]interface{}{"folder_id": folderID.ValueString()})
+}
+
+// ImportState imports the permissions of the folder with the given ID,
+// without propagation.
+func (r *folderPermissions) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("folder_id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("propagate_to_children"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("affected_folder_ids"), stringSet(nil))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("affected_resource_ids"), stringSet(nil))...)
+}
+
+// apply shares the folder, and its contents when propagating, so that their
+// permissions match plan. The contents are changed first, so that they can
+// still be listed when the permissions of the current user are reduced.
+func (r *folderPermissions) apply(ctx context.Context, plan *folderPermissionsModel, diags *diag.Diagnostics) {
+	folderID := plan.FolderId.ValueString()
+
+	var descendants folderDescendants
+	if plan.PropagateToChildren.ValueBool() {
+		var err error
+		descendants, err = getFolderDescendants(ctx, r.client, folderID)
+		if err != nil {
+			diags.AddError(
+				"Unable to list folder contents",
+				"Could not list the contents of folder "+folderID+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	folderIDs, resourceIDs := stringSet(descendants.FolderIDs), stringSet(descendants.ResourceIDs)
+	if (!plan.AffectedFolderIds.IsUnknown() && !plan.AffectedFolderIds.Equal(folderIDs)) ||
+		(!plan.AffectedResourceIds.IsUnknown() && !plan.AffectedResourceIds.Equal(resourceIDs)) {
+		diags.AddError(
+			"Folder contents changed",
+			"The subfolders or passwords of folder "+folderID+" changed since the plan was made, plan again to include them.",
+		)
+		return
+	}
+
+	for _, resourceID := range descendants.ResourceIDs {
+		setPermissions(ctx, r.client, "password", resourceID, plan.Permissions, diags)
+		if diags.HasError() {
+			return
+		}
+	}
+
+	for i := len(descendants.FolderIDs) - 1; i >= 0; i-- {
+		setPermissions(ctx, r.client, "folder", descendants.FolderIDs[i], plan.Permissions, diags)
+		if diags.HasError() {
+			return
+		}
+	}
+
+	setPermissions(ctx, r.client, "folder", folderID, plan.Permissions, diags)
+	if diags.HasError() {
+		return
+	}
+
+	plan.AffectedFolderIds = folderIDs
+	plan.AffectedResourceIds = resourceIDs
+}
+
+// getFolderDescendants lists the subfolders and passwords below a folder,
+// level by level.
+func getFolderDescendants(ctx context.Context, client *tools.PassboltClient, folderID string) (folderDescendants, error) {
+	var descendants folderDescendants
+
+	parents := []string{folderID}
+	for len(parents) > 0 {
+		var folders []api.Folder
+		var resources []api.Resource
+		err := client.Do(ctx, func() (err error) {
+			folders, err = client.Client.GetFolders(ctx, &api.GetFoldersOptions{FilterHasParent: parents})
+			if err != nil {
+				return err
+			}
+			resources, err = client.Client.GetResources(ctx, &api.GetResourcesOptions{FilterHasParent: parents})
+			return err
+		})
+		if err != nil {
+			return descendants, err
+		}
+
+		parents = nil
+		for _, folder := range folders {
+			descendants.FolderIDs = append(descendants.FolderIDs, folder.ID)
+			parents = append(parents, folder.ID)
+		}
+		for _, res := range resources {
+			descendants.ResourceIDs = append(descendants.ResourceIDs, res.ID)
+		}
+	}
+
+	return descendants, nil
+}
+
+// stringSet converts ids to a set, which is empty rather than null for no ids.
+func stringSet(ids []string) types.Set {
+	elements := make([]attr.Value, 0, len(ids))
+	for _, id := range ids {
+		elements = append(elements, types.StringValue(id))
+	}
+	return types.SetValueMust(types.StringType, elements)
+}
@@ -3,6 +3,8 @@ package provider
 import (
 	"context"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -12,8 +14,9 @@ import (
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &folderResource{}
-	_ resource.ResourceWithConfigure = &folderResource{}
+	_ resource.Resource                = &folderResource{}
+	_ resource.ResourceWithConfigure   = &folderResource{}
+	_ resource.ResourceWithImportState = &folderResource{}
 )
 
 // NewFolderResource is a helper function to simplify the provider implementation.
@@ -28,9 +31,10 @@ type folderResource struct {
 
 // created, modified, created_by, modified_by, and folder_parent_id
 type foldersModelCreate struct {
-	ID           types.String `tfsdk:"id"`
-	Name         types.String `tfsdk:"name"`
-	FolderParentId types.String `tfsdk:"folder_parent_id"`
+	ID             types.String   `tfsdk:"id"`
+	Name           types.String   `tfsdk:"name"`
+	FolderParentId types.String   `tfsdk:"folder_parent_id"`
+	Timeouts       timeouts.Value `tfsdk:"timeouts"`
 }
 
 // Configure adds the provider configured client to the resource.
@@ -58,7 +62,7 @@ func (r *folderResource) Metadata(_ context.Context, req resource.MetadataReques
 }
 
 // Schema defines the schema for the resource.
-func (r *folderResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *folderResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -71,6 +75,14 @@ func (r *folderResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				Optional: true,
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -84,30 +96,39 @@ func (r *folderResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	/*
-	parent, err := r.client.Client.GetFolder(r.client.Context, plan.FolderParentId.ValueString(),nil)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to Read folder", "",
-		)
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	folders, err := r.client.Client.GetFolders(ctx, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get folders", "")
-		return
-	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	/*
+		parent, err := r.client.Client.GetFolder(ctx, plan.FolderParentId.ValueString(),nil)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to Read folder", "",
+			)
+			return
+		}
+
+		folders, err := r.client.Client.GetFolders(ctx, nil)
+		if err != nil {
+			resp.Diagnostics.AddError("Cannot get folders", "")
+			return
+		}
 
-	var folderId string
-	if !plan.FolderParentId.IsUnknown() && !plan.FolderParentId.IsNull() {
-		for _, folder := range folders {
-			if folder.ID == plan.FolderParentId.ValueString() {
-				folderId = folder.ID
+		var folderId string
+		if !plan.FolderParentId.IsUnknown() && !plan.FolderParentId.IsNull() {
+			for _, folder := range folders {
+				if folder.ID == plan.FolderParentId.ValueString() {
+					folderId = folder.ID
+				}
 			}
 		}
-	}
-*/
+	*/
 	// Generate API request body from plan
 	var folder = api.Folder{
 		FolderParentID: plan.FolderParentId.ValueString(),
@@ -115,7 +136,11 @@ func (r *folderResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	// Create new order
-	cFolder, errCreate := r.client.Client.CreateFolder(r.client.Context, folder)
+	var cFolder *api.Folder
+	errCreate := r.client.Do(ctx, func() (err error) {
+		cFolder, err = r.client.Client.CreateFolder(ctx, folder)
+		return err
+	})
 	if errCreate != nil {
 		resp.Diagnostics.AddError(
 			"Error creating folder",
@@ -144,10 +169,28 @@ func (r *folderResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	folder, err := r.client.Client.GetFolder(r.client.Context, plan.ID.ValueString(),nil)
+	readTimeout, diags := plan.Timeouts.Read(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	var folder *api.Folder
+	err := r.client.Do(ctx, func() (err error) {
+		folder, err = r.client.Client.GetFolder(ctx, plan.ID.ValueString(), nil)
+		return err
+	})
+	if tools.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Unable to Read folder", "",
+			"Unable to read folder",
+			"Could not read folder "+plan.ID.ValueString()+", unexpected error: "+err.Error(),
 		)
 		return
 	}
@@ -155,11 +198,10 @@ func (r *folderResource) Read(ctx context.Context, req resource.ReadRequest, res
 	folderState := foldersModelCreate{
 		ID:             types.StringValue(folder.ID),
 		Name:           types.StringValue(folder.Name),
-		FolderParentId: types.StringValue(folder.FolderParentID),
+		FolderParentId: stringValueOrNull(folder.FolderParentID),
+		Timeouts:       plan.Timeouts,
 	}
 
-
-
 	// Set state
 	diag := resp.State.Set(ctx, folderState)
 	resp.Diagnostics.Append(diag...)
@@ -176,6 +218,16 @@ func (r *folderResource) Update(ctx context.Context, req resource.UpdateRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	var state foldersModelCreate
 	diags = req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -184,7 +236,9 @@ func (r *folderResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	if state.FolderParentId != plan.FolderParentId {
-		errMove := r.client.Client.MoveFolder(r.client.Context, state.ID.ValueString(), plan.FolderParentId.ValueString())
+		errMove := r.client.Do(ctx, func() error {
+			return r.client.Client.MoveFolder(ctx, state.ID.ValueString(), plan.FolderParentId.ValueString())
+		})
 		if errMove != nil {
 			resp.Diagnostics.AddError(
 				"Unable to move folder ", "",
@@ -200,11 +254,15 @@ func (r *folderResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	// Create new order
-	cFolder, errUpdate := r.client.Client.UpdateFolder(r.client.Context, state.ID.ValueString(), folder)
+	var cFolder *api.Folder
+	errUpdate := r.client.Do(ctx, func() (err error) {
+		cFolder, err = r.client.Client.UpdateFolder(ctx, state.ID.ValueString(), folder)
+		return err
+	})
 	if errUpdate != nil {
 		resp.Diagnostics.AddError(
 			"Error updating folder",
-						"Could not update folder, unexpected error: "+errUpdate.Error(),
+			"Could not update folder, unexpected error: "+errUpdate.Error(),
 		)
 		return
 	}
@@ -212,7 +270,8 @@ func (r *folderResource) Update(ctx context.Context, req resource.UpdateRequest,
 	folderState := foldersModelCreate{
 		ID:             types.StringValue(cFolder.ID),
 		Name:           types.StringValue(cFolder.Name),
-		FolderParentId: types.StringValue(cFolder.FolderParentID),
+		FolderParentId: stringValueOrNull(cFolder.FolderParentID),
+		Timeouts:       plan.Timeouts,
 	}
 
 	// Map response body to schema and populate Computed attribute values
@@ -234,12 +293,30 @@ func (r *folderResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	err := r.client.Client.DeleteFolder(ctx, state.ID.ValueString())
-	if err != nil {
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	err := r.client.Do(ctx, func() error {
+		return r.client.Client.DeleteFolder(ctx, state.ID.ValueString())
+	})
+	// A retried delete finds the object already gone.
+	if err != nil && !tools.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Error deleting Folder",
 			"Could not delete Folder, unexpected error: "+err.Error(),
 		)
 		return
+	}
 }
+
+// ImportState imports an existing folder by its Passbolt ID.
+func (r *folderResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
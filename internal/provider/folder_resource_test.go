@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net/http"
+	"testing"
+)
+
+const testFolderID = "5e0c3d4f-7a81-4b92-8c3d-2e3f4a5b6c55"
+
+func TestFolderRead(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		wantRemoved bool
+		wantDetail  string
+	}{
+		{
+			name: "deleted",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				writeAPIError(w, http.StatusNotFound, "The folder does not exist.")
+			},
+			wantRemoved: true,
+		},
+		{
+			name: "error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				writeAPIError(w, http.StatusInternalServerError, "Internal error.")
+			},
+			wantDetail: "Internal error.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			server.handleFunc("GET /folders/"+testFolderID+".json", tt.handler)
+			r := &folderResource{client: server.client()}
+
+			state := stateOf(t, r, foldersModelCreate{ID: types.StringValue(testFolderID), Timeouts: nullTimeouts()})
+			resp := resource.ReadResponse{State: state}
+			r.Read(context.Background(), resource.ReadRequest{State: state}, &resp)
+
+			assertReadResult(t, resp, tt.wantRemoved, tt.wantDetail)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
 	"terraform-provider-passbolt/tools"
 )
 
@@ -106,7 +107,11 @@ func (d *foldersDataSource) Schema(_ context.Context, _ datasource.SchemaRequest
 func (d *foldersDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var state foldersDataSourceModel
 
-	folders, err := d.client.Client.GetFolders(d.client.Context, nil)
+	var folders []api.Folder
+	err := d.client.Do(ctx, func() (err error) {
+		folders, err = d.client.Client.GetFolders(ctx, nil)
+		return err
+	})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Read folders", "",
@@ -0,0 +1,322 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/helper"
+	"strings"
+	"terraform-provider-passbolt/tools"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &groupMembershipResource{}
+	_ resource.ResourceWithConfigure   = &groupMembershipResource{}
+	_ resource.ResourceWithImportState = &groupMembershipResource{}
+)
+
+// NewGroupMembershipResource is a helper function to simplify the provider implementation.
+func NewGroupMembershipResource() resource.Resource {
+	return &groupMembershipResource{}
+}
+
+// groupMembershipResource manages the membership of a single user in a group.
+type groupMembershipResource struct {
+	client *tools.PassboltClient
+}
+
+type groupMembershipModel struct {
+	ID       types.String   `tfsdk:"id"`
+	GroupId  types.String   `tfsdk:"group_id"`
+	UserId   types.String   `tfsdk:"user_id"`
+	IsAdmin  types.Bool     `tfsdk:"is_admin"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *groupMembershipResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*tools.PassboltClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *passboltClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// Metadata returns the resource type name.
+func (r *groupMembershipResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_group_membership"
+}
+
+// Schema defines the schema for the resource.
+func (r *groupMembershipResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Adds a user to a group. Do not combine with the members of a passbolt_group managing the same group.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier of the membership in the format <group_id>:<user_id>.",
+				Computed:    true,
+			},
+			"group_id": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"user_id": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"is_admin": schema.BoolAttribute{
+				Description: "Whether the user manages the group.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// Create adds the user to the group. Secrets shared with the group are
+// encrypted for the user as part of the update.
+func (r *groupMembershipResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan groupMembershipModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	groupID, userID := plan.GroupId.ValueString(), plan.UserId.ValueString()
+
+	_, memberships, err := getGroup(ctx, r.client, groupID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read group",
+			"Could not read group "+groupID+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+	if findMembership(memberships, userID) != nil {
+		resp.Diagnostics.AddError(
+			"User already in group",
+			"The user "+userID+" is already a member of group "+groupID+", import the membership to manage it.",
+		)
+		return
+	}
+
+	err = r.updateMembership(ctx, groupID, helper.GroupMembershipOperation{UserID: userID, IsGroupManager: plan.IsAdmin.ValueBool()})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error adding group member",
+			"Could not add user "+userID+" to group "+groupID+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+	plan.ID = types.StringValue(groupID + ":" + userID)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *groupMembershipResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state groupMembershipModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	_, memberships, err := getGroup(ctx, r.client, state.GroupId.ValueString())
+	if tools.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read group",
+			"Could not read group "+state.GroupId.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// The user was removed from the group outside of Terraform.
+	membership := findMembership(memberships, state.UserId.ValueString())
+	if membership == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.ID = types.StringValue(state.GroupId.ValueString() + ":" + state.UserId.ValueString())
+	state.IsAdmin = types.BoolValue(membership.IsGroupManager)
+
+	// Set state
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update changes whether the user manages the group.
+func (r *groupMembershipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan groupMembershipModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state groupMembershipModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	if !plan.IsAdmin.Equal(state.IsAdmin) {
+		err := r.updateMembership(ctx, plan.GroupId.ValueString(), helper.GroupMembershipOperation{UserID: plan.UserId.ValueString(), IsGroupManager: plan.IsAdmin.ValueBool()})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to update group member",
+				"Could not update user "+plan.UserId.ValueString()+" in group "+plan.GroupId.ValueString()+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+	plan.ID = state.ID
+
+	// Set state
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the user from the group.
+func (r *groupMembershipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state groupMembershipModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	groupID, userID := state.GroupId.ValueString(), state.UserId.ValueString()
+
+	// Nothing is left to remove when the group or membership is gone.
+	_, memberships, err := getGroup(ctx, r.client, groupID)
+	if tools.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read group",
+			"Could not read group "+groupID+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+	if findMembership(memberships, userID) == nil {
+		return
+	}
+
+	err = r.updateMembership(ctx, groupID, helper.GroupMembershipOperation{UserID: userID, Delete: true})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error removing group member",
+			"Could not remove user "+userID+" from group "+groupID+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState imports a membership identified by "group_id/user_id" or its ID.
+func (r *groupMembershipResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	groupID, userID, err := splitImportID(strings.Replace(req.ID, ":", "/", 1), "group_id/user_id")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), groupID+":"+userID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("group_id"), groupID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user_id"), userID)...)
+}
+
+// updateMembership applies a single membership change through the group
+// update dry-run, which lists the secrets to encrypt for new members.
+func (r *groupMembershipResource) updateMembership(ctx context.Context, groupID string, operation helper.GroupMembershipOperation) error {
+	return r.client.Do(ctx, func() error {
+		return helper.UpdateGroup(ctx, r.client.Client, groupID, "", []helper.GroupMembershipOperation{operation})
+	})
+}
+
+// findMembership returns the membership of the user, or nil.
+func findMembership(memberships []helper.GroupMembership, userID string) *helper.GroupMembership {
+	for i := range memberships {
+		if memberships[i].UserID == userID {
+			return &memberships[i]
+		}
+	}
+	return nil
+}
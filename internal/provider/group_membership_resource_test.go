@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"testing"
+)
+
+// membershipOf returns the state of the membership of userID in the test
+// group.
+func membershipOf(userID string, isAdmin bool) groupMembershipModel {
+	return groupMembershipModel{
+		ID:       types.StringValue(testGroupID + ":" + userID),
+		GroupId:  types.StringValue(testGroupID),
+		UserId:   types.StringValue(userID),
+		IsAdmin:  types.BoolValue(isAdmin),
+		Timeouts: nullTimeouts(),
+	}
+}
+
+func TestGroupMembershipCreate(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		wantChanges []groupChange
+		wantErr     string
+	}{
+		{name: "new member", userID: testUser3ID, wantChanges: []groupChange{{UserID: testUser3ID, IsAdmin: true}}},
+		{name: "already a member", userID: testUser2ID, wantErr: "User already in group"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			server.handleGroup(testGroupID, "Ops", testMemberships())
+			r := &groupMembershipResource{client: server.client()}
+
+			plan := membershipOf(tt.userID, true)
+			plan.ID = types.StringUnknown()
+			resp := resource.CreateResponse{State: emptyState(t, r)}
+			r.Create(context.Background(), resource.CreateRequest{Plan: planOf(t, r, plan)}, &resp)
+
+			updates := server.requested("PUT /groups/" + testGroupID + ".json")
+			if tt.wantErr != "" {
+				if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != tt.wantErr {
+					t.Fatalf("Create() diagnostics = %v, want error %q", resp.Diagnostics, tt.wantErr)
+				}
+				if len(updates) != 0 {
+					t.Errorf("Create() updated the group %d times, want no update", len(updates))
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Create() diagnostics = %v", resp.Diagnostics)
+			}
+			if len(updates) != 1 {
+				t.Fatalf("Create() updated the group %d times, want once", len(updates))
+			}
+			if got := groupChanges(groupUpdate(t, updates[0])); len(got) != 1 || got[0] != tt.wantChanges[0] {
+				t.Errorf("Create() changes = %+v, want %+v", got, tt.wantChanges)
+			}
+
+			var got groupMembershipModel
+			resp.Diagnostics.Append(resp.State.Get(context.Background(), &got)...)
+			if want := testGroupID + ":" + tt.userID; got.ID.ValueString() != want {
+				t.Errorf("Create() ID = %s, want %s", got.ID, want)
+			}
+		})
+	}
+}
+
+func TestGroupMembershipRead(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       groupMembershipModel
+		notFound    bool
+		wantRemoved bool
+		wantIsAdmin bool
+	}{
+		{name: "member", state: membershipOf(testUser2ID, true), wantIsAdmin: false},
+		{name: "manager", state: membershipOf(testUserID, false), wantIsAdmin: true},
+		{name: "removed from group", state: membershipOf(testUser3ID, false), wantRemoved: true},
+		{name: "group deleted", state: membershipOf(testUser2ID, false), notFound: true, wantRemoved: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			server.handleGroup(testGroupID, "Ops", testMemberships())
+			if tt.notFound {
+				server.handleNotFound("GET /groups/" + testGroupID + ".json")
+			}
+			r := &groupMembershipResource{client: server.client()}
+
+			state := stateOf(t, r, tt.state)
+			resp := resource.ReadResponse{State: state}
+			r.Read(context.Background(), resource.ReadRequest{State: state}, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Read() diagnostics = %v", resp.Diagnostics)
+			}
+			if resp.State.Raw.IsNull() != tt.wantRemoved {
+				t.Fatalf("Read() removed the membership %v, want %v", resp.State.Raw.IsNull(), tt.wantRemoved)
+			}
+			if tt.wantRemoved {
+				return
+			}
+
+			var got groupMembershipModel
+			resp.Diagnostics.Append(resp.State.Get(context.Background(), &got)...)
+			if got.IsAdmin.ValueBool() != tt.wantIsAdmin {
+				t.Errorf("Read() is_admin = %v, want %v", got.IsAdmin, tt.wantIsAdmin)
+			}
+		})
+	}
+}
+
+func TestGroupMembershipDelete(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		notFound    bool
+		wantChanges []groupChange
+	}{
+		{name: "member", userID: testUser2ID, wantChanges: []groupChange{{ID: "m2", Delete: true}}},
+		{name: "already removed", userID: testUser3ID},
+		{name: "group deleted", userID: testUser2ID, notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			server.handleGroup(testGroupID, "Ops", testMemberships())
+			if tt.notFound {
+				server.handleNotFound("GET /groups/" + testGroupID + ".json")
+			}
+			r := &groupMembershipResource{client: server.client()}
+
+			resp := resource.DeleteResponse{State: stateOf(t, r, membershipOf(tt.userID, false))}
+			r.Delete(context.Background(), resource.DeleteRequest{State: resp.State}, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Delete() diagnostics = %v", resp.Diagnostics)
+			}
+
+			updates := server.requested("PUT /groups/" + testGroupID + ".json")
+			if len(updates) != len(tt.wantChanges) {
+				t.Fatalf("Delete() updated the group %d times, want %d", len(updates), len(tt.wantChanges))
+			}
+			if len(updates) == 1 {
+				if got := groupChanges(groupUpdate(t, updates[0])); len(got) != 1 || got[0] != tt.wantChanges[0] {
+					t.Errorf("Delete() changes = %+v, want %+v", got, tt.wantChanges)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupMembershipImportState(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{name: "ID", id: testGroupID + ":" + testUserID},
+		{name: "group and user", id: testGroupID + "/" + testUserID},
+		{name: "group only", id: testGroupID, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &groupMembershipResource{}
+			resp := resource.ImportStateResponse{State: emptyState(t, r)}
+			r.ImportState(context.Background(), resource.ImportStateRequest{ID: tt.id}, &resp)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Fatalf("ImportState(%q) diagnostics = %v, wantErr %v", tt.id, resp.Diagnostics, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var got groupMembershipModel
+			resp.Diagnostics.Append(resp.State.Get(context.Background(), &got)...)
+			if got.ID.ValueString() != testGroupID+":"+testUserID || got.GroupId.ValueString() != testGroupID || got.UserId.ValueString() != testUserID {
+				t.Errorf("ImportState(%q) = %s, %s, %s", tt.id, got.ID, got.GroupId, got.UserId)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/helper"
+	"terraform-provider-passbolt/tools"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &groupResource{}
+	_ resource.ResourceWithConfigure   = &groupResource{}
+	_ resource.ResourceWithImportState = &groupResource{}
+)
+
+// NewGroupResource is a helper function to simplify the provider implementation.
+func NewGroupResource() resource.Resource {
+	return &groupResource{}
+}
+
+// groupResource is the resource implementation.
+type groupResource struct {
+	client *tools.PassboltClient
+}
+
+type groupModel struct {
+	ID       types.String   `tfsdk:"id"`
+	Name     types.String   `tfsdk:"name"`
+	Managers types.Set      `tfsdk:"managers"`
+	Members  types.Set      `tfsdk:"members"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *groupResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*tools.PassboltClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *passboltClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// Metadata returns the resource type name.
+func (r *groupResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_group"
+}
+
+// Schema defines the schema for the resource.
+func (r *groupResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"name": schema.StringAttribute{
+				Required: true,
+			},
+			"managers": schema.SetAttribute{
+				Description: "IDs of the users managing the group.",
+				Required:    true,
+				ElementType: types.StringType,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"members": schema.SetAttribute{
+				Description: "IDs of the other users in the group. When not set, members are left untouched so they can be managed with passbolt_group_membership.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// Create a new resource.
+func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan groupModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	roles := plan.roles(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	operations := make([]helper.GroupMembershipOperation, 0, len(roles))
+	for userID, isManager := range roles {
+		operations = append(operations, helper.GroupMembershipOperation{UserID: userID, IsGroupManager: isManager})
+	}
+
+	var groupID string
+	err := r.client.Do(ctx, func() (err error) {
+		groupID, err = helper.CreateGroup(ctx, r.client.Client, plan.Name.ValueString(), operations)
+		return err
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating group",
+			"Could not create group, unexpected error: "+err.Error(),
+		)
+		return
+	}
+	plan.ID = types.StringValue(groupID)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *groupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state groupModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	name, memberships, err := getGroup(ctx, r.client, state.ID.ValueString())
+	if tools.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read group",
+			"Could not read group "+state.ID.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var managers, members []string
+	for _, membership := range memberships {
+		if membership.IsGroupManager {
+			managers = append(managers, membership.UserID)
+		} else {
+			members = append(members, membership.UserID)
+		}
+	}
+
+	state.Name = types.StringValue(name)
+	state.Managers = stringSet(managers)
+	if !state.Members.IsNull() {
+		state.Members = stringSet(members)
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+// Secrets shared with the group are encrypted for new members as part of the
+// update.
+func (r *groupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan groupModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state groupModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	roles := plan.roles(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, memberships, err := getGroup(ctx, r.client, state.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read group",
+			"Could not read group "+state.ID.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var operations []helper.GroupMembershipOperation
+	current := make(map[string]bool, len(memberships))
+	for _, membership := range memberships {
+		current[membership.UserID] = true
+
+		isManager, wanted := roles[membership.UserID]
+		switch {
+		case wanted && isManager != membership.IsGroupManager:
+			operations = append(operations, helper.GroupMembershipOperation{UserID: membership.UserID, IsGroupManager: isManager})
+		case wanted:
+			// The membership is unchanged.
+		case plan.Members.IsNull() && membership.IsGroupManager:
+			// Members are not managed, so a former manager stays a member.
+			operations = append(operations, helper.GroupMembershipOperation{UserID: membership.UserID})
+		case !plan.Members.IsNull() || membership.IsGroupManager:
+			operations = append(operations, helper.GroupMembershipOperation{UserID: membership.UserID, Delete: true})
+		}
+	}
+	for userID, isManager := range roles {
+		if !current[userID] {
+			operations = append(operations, helper.GroupMembershipOperation{UserID: userID, IsGroupManager: isManager})
+		}
+	}
+
+	if len(operations) > 0 || !plan.Name.Equal(state.Name) {
+		err = r.client.Do(ctx, func() error {
+			return helper.UpdateGroup(ctx, r.client.Client, state.ID.ValueString(), plan.Name.ValueString(), operations)
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to update group",
+				"Could not update group "+state.ID.ValueString()+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+	plan.ID = state.ID
+
+	// Set state
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *groupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state groupModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	err := r.client.Do(ctx, func() error {
+		return helper.DeleteGroup(ctx, r.client.Client, state.ID.ValueString())
+	})
+	if err != nil && !tools.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error deleting group",
+			"Could not delete group, unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState imports an existing group by its Passbolt ID. Members are
+// imported as managed, Read fills them in with the managers.
+func (r *groupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("members"), stringSet(nil))...)
+}
+
+// roles maps the ID of every planned user to whether they manage the group.
+func (m groupModel) roles(ctx context.Context, diags *diag.Diagnostics) map[string]bool {
+	var managers, members []string
+	diags.Append(m.Managers.ElementsAs(ctx, &managers, false)...)
+	if !m.Members.IsNull() {
+		diags.Append(m.Members.ElementsAs(ctx, &members, false)...)
+	}
+	if diags.HasError() {
+		return nil
+	}
+
+	roles := make(map[string]bool, len(managers)+len(members))
+	for _, userID := range managers {
+		roles[userID] = true
+	}
+	for _, userID := range members {
+		if roles[userID] {
+			diags.AddAttributeError(path.Root("members"), "Invalid group members", "User "+userID+" is listed both as manager and as member.")
+			return nil
+		}
+		roles[userID] = false
+	}
+	return roles
+}
+
+// getGroup returns the name and memberships of a group. helper.GetGroup does
+// not report missing groups as such, so the group is looked up first.
+func getGroup(ctx context.Context, client *tools.PassboltClient, groupID string) (string, []helper.GroupMembership, error) {
+	var name string
+	var memberships []helper.GroupMembership
+	err := client.Do(ctx, func() error {
+		_, err := client.Client.GetGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+
+		name, memberships, err = helper.GetGroup(ctx, client.Client, groupID)
+		return err
+	})
+	return name, memberships, err
+}
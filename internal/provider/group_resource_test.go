@@ -0,0 +1,237 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"sort"
+	"testing"
+)
+
+// testMemberships are the memberships of the test group: testUserID manages
+// it and testUser2ID is a member.
+func testMemberships() []api.GroupMembership {
+	return []api.GroupMembership{
+		{ID: "m1", UserID: testUserID, IsAdmin: true},
+		{ID: "m2", UserID: testUser2ID},
+	}
+}
+
+// groupChange is the part of a membership change compared by tests.
+type groupChange struct {
+	ID      string
+	UserID  string
+	IsAdmin bool
+	Delete  bool
+}
+
+// groupChanges returns the membership changes of update, sorted.
+func groupChanges(update api.GroupUpdate) []groupChange {
+	changes := make([]groupChange, 0, len(update.GroupChanges))
+	for _, change := range update.GroupChanges {
+		changes = append(changes, groupChange{ID: change.ID, UserID: change.UserID, IsAdmin: change.IsAdmin, Delete: change.Delete})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID+changes[i].UserID < changes[j].ID+changes[j].UserID })
+	return changes
+}
+
+func TestGroupImportState(t *testing.T) {
+	server := newFakePassbolt(t)
+	server.handleGroup(testGroupID, "Ops", testMemberships())
+	r := &groupResource{client: server.client()}
+
+	imported := resource.ImportStateResponse{State: emptyState(t, r)}
+	r.ImportState(context.Background(), resource.ImportStateRequest{ID: testGroupID}, &imported)
+	if imported.Diagnostics.HasError() {
+		t.Fatalf("ImportState() diagnostics = %v", imported.Diagnostics)
+	}
+
+	resp := resource.ReadResponse{State: imported.State}
+	r.Read(context.Background(), resource.ReadRequest{State: imported.State}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read() diagnostics = %v", resp.Diagnostics)
+	}
+
+	var got groupModel
+	resp.Diagnostics.Append(resp.State.Get(context.Background(), &got)...)
+	if got.ID.ValueString() != testGroupID || got.Name.ValueString() != "Ops" {
+		t.Errorf("imported group %s named %s, want %s named Ops", got.ID, got.Name, testGroupID)
+	}
+	if !got.Managers.Equal(stringSet([]string{testUserID})) {
+		t.Errorf("imported managers = %v, want %s", got.Managers, testUserID)
+	}
+	if !got.Members.Equal(stringSet([]string{testUser2ID})) {
+		t.Errorf("imported members = %v, want %s", got.Members, testUser2ID)
+	}
+}
+
+func TestGroupRead(t *testing.T) {
+	tests := []struct {
+		name        string
+		members     types.Set
+		notFound    bool
+		wantMembers types.Set
+	}{
+		{name: "managed members", members: stringSet(nil), wantMembers: stringSet([]string{testUser2ID})},
+		{name: "unmanaged members", members: types.SetNull(types.StringType), wantMembers: types.SetNull(types.StringType)},
+		{name: "deleted", members: stringSet(nil), notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			server.handleGroup(testGroupID, "Ops", testMemberships())
+			if tt.notFound {
+				server.handleNotFound("GET /groups/" + testGroupID + ".json")
+			}
+			r := &groupResource{client: server.client()}
+
+			state := stateOf(t, r, groupModel{
+				ID:       types.StringValue(testGroupID),
+				Name:     types.StringValue("Old name"),
+				Managers: stringSet([]string{testUser2ID}),
+				Members:  tt.members,
+				Timeouts: nullTimeouts(),
+			})
+			resp := resource.ReadResponse{State: state}
+			r.Read(context.Background(), resource.ReadRequest{State: state}, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Read() diagnostics = %v", resp.Diagnostics)
+			}
+
+			if tt.notFound {
+				if !resp.State.Raw.IsNull() {
+					t.Error("Read() kept the deleted group in the state")
+				}
+				return
+			}
+
+			var got groupModel
+			resp.Diagnostics.Append(resp.State.Get(context.Background(), &got)...)
+			if got.Name.ValueString() != "Ops" {
+				t.Errorf("Read() name = %s, want Ops", got.Name)
+			}
+			if !got.Managers.Equal(stringSet([]string{testUserID})) {
+				t.Errorf("Read() managers = %v, want %s", got.Managers, testUserID)
+			}
+			if !got.Members.Equal(tt.wantMembers) {
+				t.Errorf("Read() members = %v, want %v", got.Members, tt.wantMembers)
+			}
+		})
+	}
+}
+
+func TestGroupUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		groupName   string
+		managers    []string
+		members     types.Set
+		wantChanges []groupChange
+		wantUpdate  bool
+	}{
+		{
+			name:      "unchanged",
+			groupName: "Ops",
+			managers:  []string{testUserID},
+			members:   stringSet([]string{testUser2ID}),
+		},
+		{
+			name:       "renamed",
+			groupName:  "Operations",
+			managers:   []string{testUserID},
+			members:    stringSet([]string{testUser2ID}),
+			wantUpdate: true,
+		},
+		{
+			name:        "member removed",
+			groupName:   "Ops",
+			managers:    []string{testUserID},
+			members:     stringSet(nil),
+			wantChanges: []groupChange{{ID: "m2", Delete: true}},
+			wantUpdate:  true,
+		},
+		{
+			name:      "manager replaced with unmanaged members",
+			groupName: "Ops",
+			managers:  []string{testUser2ID},
+			members:   types.SetNull(types.StringType),
+			// The former manager stays a member.
+			wantChanges: []groupChange{{ID: "m1"}, {ID: "m2", IsAdmin: true}},
+			wantUpdate:  true,
+		},
+		{
+			name:      "manager replaced with managed members",
+			groupName: "Ops",
+			managers:  []string{testUser2ID},
+			members:   stringSet(nil),
+			wantChanges: []groupChange{
+				{ID: "m1", Delete: true},
+				{ID: "m2", IsAdmin: true},
+			},
+			wantUpdate: true,
+		},
+		{
+			name:        "member added",
+			groupName:   "Ops",
+			managers:    []string{testUserID},
+			members:     stringSet([]string{testUser2ID, testUser3ID}),
+			wantChanges: []groupChange{{UserID: testUser3ID}},
+			wantUpdate:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			server.handleGroup(testGroupID, "Ops", testMemberships())
+			r := &groupResource{client: server.client()}
+
+			state := groupModel{
+				ID:       types.StringValue(testGroupID),
+				Name:     types.StringValue("Ops"),
+				Managers: stringSet([]string{testUserID}),
+				Members:  stringSet([]string{testUser2ID}),
+				Timeouts: nullTimeouts(),
+			}
+			plan := state
+			plan.Name = types.StringValue(tt.groupName)
+			plan.Managers = stringSet(tt.managers)
+			plan.Members = tt.members
+
+			req := resource.UpdateRequest{Plan: planOf(t, r, plan), State: stateOf(t, r, state)}
+			resp := resource.UpdateResponse{State: emptyState(t, r)}
+			r.Update(context.Background(), req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Update() diagnostics = %v", resp.Diagnostics)
+			}
+
+			updates := server.requested("PUT /groups/" + testGroupID + ".json")
+			if !tt.wantUpdate {
+				if len(updates) != 0 {
+					t.Fatalf("Update() updated the group %d times, want no update", len(updates))
+				}
+				return
+			}
+			if len(updates) != 1 {
+				t.Fatalf("Update() updated the group %d times, want once", len(updates))
+			}
+
+			update := groupUpdate(t, updates[0])
+			if update.Name != tt.groupName {
+				t.Errorf("Update() name = %q, want %q", update.Name, tt.groupName)
+			}
+			got := groupChanges(update)
+			if len(got) != len(tt.wantChanges) {
+				t.Fatalf("Update() changes = %+v, want %+v", got, tt.wantChanges)
+			}
+			for i := range got {
+				if got[i] != tt.wantChanges[i] {
+					t.Errorf("Update() changes = %+v, want %+v", got, tt.wantChanges)
+					break
+				}
+			}
+		})
+	}
+}
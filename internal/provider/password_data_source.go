@@ -26,6 +26,15 @@ type passwordDataSource struct {
 	client *tools.PassboltClient
 }
 
+type passwordDataSourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Name           types.String `tfsdk:"name"`
+	Username       types.String `tfsdk:"username"`
+	Uri            types.String `tfsdk:"uri"`
+	FolderParentId types.String `tfsdk:"folder_parent_id"`
+	Password       types.String `tfsdk:"password"`
+	Description    types.String `tfsdk:"description"`
+}
 
 // Configure adds the provider configured client to the data source.
 func (d *passwordDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
@@ -83,13 +92,17 @@ func (d *passwordDataSource) Schema(_ context.Context, _ datasource.SchemaReques
 // Read refreshes the Terraform state with the latest data.
 func (d *passwordDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 
-	var conf passwordModel
+	var conf passwordDataSourceModel
 	diags := req.Config.Get(ctx, &conf)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	folderParentID, name, username, uri, password, description , err  := helper.GetResource(ctx, d.client.Client, conf.ID.ValueString())
+	var folderParentID, name, username, uri, password, description string
+	err := d.client.Do(ctx, func() (err error) {
+		folderParentID, name, username, uri, password, description, err = helper.GetResource(ctx, d.client.Client, conf.ID.ValueString())
+		return err
+	})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Read password ", "",
@@ -97,18 +110,16 @@ func (d *passwordDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
-	passwordState := passwordModel{
+	passwordState := passwordDataSourceModel{
 		ID:             conf.ID,
 		Name:           types.StringValue(name),
-		Username: 		types.StringValue(username),
+		Username:       types.StringValue(username),
 		FolderParentId: types.StringValue(folderParentID),
-		Uri:          types.StringValue(uri),
-		Description:     types.StringValue(description),
-		Password:		types.StringValue(password),
+		Uri:            types.StringValue(uri),
+		Description:    types.StringValue(description),
+		Password:       types.StringValue(password),
 	}
 
-
-
 	// Set state
 	diag := resp.State.Set(ctx, passwordState)
 	resp.Diagnostics.Append(diag...)
@@ -3,17 +3,25 @@ package provider
 import (
 	"context"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
 	"github.com/passbolt/go-passbolt/helper"
 	"terraform-provider-passbolt/tools"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &passwordResource{}
-	_ resource.ResourceWithConfigure = &passwordResource{}
+	_ resource.Resource                = &passwordResource{}
+	_ resource.ResourceWithConfigure   = &passwordResource{}
+	_ resource.ResourceWithImportState = &passwordResource{}
+	_ resource.ResourceWithModifyPlan  = &passwordResource{}
 )
 
 // NewPasswordResource is a helper function to simplify the provider implementation.
@@ -27,13 +35,26 @@ type passwordResource struct {
 }
 
 type passwordModel struct {
-	ID           types.String `tfsdk:"id"`
-	Name         types.String `tfsdk:"name"`
-	Username     types.String `tfsdk:"username"`
-	Uri          types.String `tfsdk:"uri"`
-	FolderParentId types.String `tfsdk:"folder_parent_id"`
-	Password     types.String `tfsdk:"password"`
-	Description     types.String `tfsdk:"description"`
+	ID             types.String         `tfsdk:"id"`
+	Name           types.String         `tfsdk:"name"`
+	Username       types.String         `tfsdk:"username"`
+	Uri            types.String         `tfsdk:"uri"`
+	FolderParentId types.String         `tfsdk:"folder_parent_id"`
+	Password       types.String         `tfsdk:"password"`
+	Description    types.String         `tfsdk:"description"`
+	Share          []passwordShareModel `tfsdk:"share"`
+	Timeouts       timeouts.Value       `tfsdk:"timeouts"`
+}
+
+// passwordShareModel is a group or user the password is shared with.
+type passwordShareModel struct {
+	ShareGroupId   types.String `tfsdk:"share_group_id"`
+	ShareUserId    types.String `tfsdk:"share_user_id"`
+	ShareGroupName types.String `tfsdk:"share_group_name"`
+	ShareUserEmail types.String `tfsdk:"share_user_email"`
+	Permission     types.String `tfsdk:"permission"`
+	ARO            types.String `tfsdk:"aro"`
+	AROID          types.String `tfsdk:"aro_id"`
 }
 
 // Configure adds the provider configured client to the resource.
@@ -62,7 +83,7 @@ func (r *passwordResource) Metadata(_ context.Context, req resource.MetadataRequ
 }
 
 // Schema defines the schema for the resource.
-func (r *passwordResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *passwordResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -87,6 +108,60 @@ func (r *passwordResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 			"description": schema.StringAttribute{
 				Optional: true,
 			},
+			"share": schema.SetNestedAttribute{
+				Description: "Groups and users the password is shared with. Other permissions are left untouched.",
+				Optional:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"share_group_id": schema.StringAttribute{
+							Description: "ID of the group. Exactly one of share_group_id, share_user_id, share_group_name and share_user_email must be set.",
+							Optional:    true,
+							Validators: []validator.String{
+								stringvalidator.ExactlyOneOf(
+									path.MatchRelative().AtParent().AtName("share_user_id"),
+									path.MatchRelative().AtParent().AtName("share_group_name"),
+									path.MatchRelative().AtParent().AtName("share_user_email"),
+								),
+							},
+						},
+						"share_user_id": schema.StringAttribute{
+							Description: "ID of the user.",
+							Optional:    true,
+						},
+						"share_group_name": schema.StringAttribute{
+							Description: "Name of the group, looked up when applying.",
+							Optional:    true,
+						},
+						"share_user_email": schema.StringAttribute{
+							Description: "Email of the user, looked up when applying.",
+							Optional:    true,
+						},
+						"permission": schema.StringAttribute{
+							Description: "Permission granted, one of read, update or owner.",
+							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(permissionNames...),
+							},
+						},
+						"aro": schema.StringAttribute{
+							Description: "Kind of principal the password is shared with, Group or User.",
+							Computed:    true,
+						},
+						"aro_id": schema.StringAttribute{
+							Description: "ID of the group or user the password is shared with.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -100,19 +175,20 @@ func (r *passwordResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
-	resourceTypes, err := r.client.Client.GetResourceTypes(ctx, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Cannot get resource types", "")
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	for _, resourceType := range resourceTypes {
-		if resourceType.Slug == "password-and-description" {
-			//		plan.ResourceTypeId = types.StringValue(resourceType.ID)
-		}
-	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 
-	folders, errFolder := r.client.Client.GetFolders(ctx, nil)
+	var folders []api.Folder
+	errFolder := r.client.Do(ctx, func() (err error) {
+		folders, err = r.client.Client.GetFolders(ctx, nil)
+		return err
+	})
 	if errFolder != nil {
 		resp.Diagnostics.AddError("Cannot get folders", "")
 		return
@@ -127,43 +203,45 @@ func (r *passwordResource) Create(ctx context.Context, req resource.CreateReques
 		}
 	}
 
-	resourceId, err := helper.CreateResource(ctx, r.client.Client, folderId, plan.Name.ValueString(), plan.Username.ValueString(), plan.Uri.ValueString(), plan.Password.ValueString(), plan.Description.ValueString())
-/*
-	var groupId string
-	if !plan.ShareGroup.IsUnknown() && !plan.FolderParentId.IsNull() {
-		groups, _ := r.client.Client.GetGroups(ctx, nil)
-
-		for _, group := range groups {
-			if group.Name == plan.ShareGroup.ValueString() {
-				groupId = group.ID
-			}
-		}
-
-		if groupId != "" {
-			var shares = []helper.ShareOperation{
-				{
-					Type:  7,
-					ARO:   "Group",
-					AROID: groupId,
-				},
-			}
+	// Resolve the principals first, so that a typo does not leave an
+	// unshared password behind.
+	shares := r.shareOperations(ctx, plan.Share, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-			shareErr := helper.ShareResource(ctx, r.client.Client, resourceId, shares)
+	var resourceId string
+	err := r.client.Do(ctx, func() (err error) {
+		resourceId, err = helper.CreateResource(ctx, r.client.Client, folderId, plan.Name.ValueString(), plan.Username.ValueString(), plan.Uri.ValueString(), plan.Password.ValueString(), plan.Description.ValueString())
+		return err
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating password",
+			"Could not create password, unexpected error: "+err.Error(),
+		)
+		return
+	}
+	plan.ID = types.StringValue(resourceId)
 
-			if shareErr != nil {
-				resp.Diagnostics.AddError("Cannot share resource", "")
-			}
+	// The password exists now, so it is kept in the state without the shares
+	// that could not be applied.
+	if len(shares) > 0 {
+		err = r.client.Do(ctx, func() error {
+			return helper.ShareResource(ctx, r.client.Client, resourceId, shares)
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error sharing password",
+				"Password "+resourceId+" was created but could not be shared, unexpected error: "+err.Error(),
+			)
+			plan.Share = nil
 		}
 	}
-*/
-	plan.ID = types.StringValue(resourceId)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -174,10 +252,29 @@ func (r *passwordResource) Read(ctx context.Context, req resource.ReadRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	folderParentID, name, username, uri, password, description , err  := helper.GetResource(ctx, r.client.Client, plan.ID.ValueString())
+
+	readTimeout, diags := plan.Timeouts.Read(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	var folderParentID, name, username, uri, password, description string
+	err := r.client.Do(ctx, func() (err error) {
+		folderParentID, name, username, uri, password, description, err = helper.GetResource(ctx, r.client.Client, plan.ID.ValueString())
+		return err
+	})
+	if tools.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Unable to Read password ", "",
+			"Unable to read password",
+			"Could not read password "+plan.ID.ValueString()+", unexpected error: "+err.Error(),
 		)
 		return
 	}
@@ -185,14 +282,29 @@ func (r *passwordResource) Read(ctx context.Context, req resource.ReadRequest, r
 	passwordState := passwordModel{
 		ID:             plan.ID,
 		Name:           types.StringValue(name),
-		Username: 		types.StringValue(username),
-		FolderParentId: types.StringValue(folderParentID),
-		Uri:          types.StringValue(uri),
-		Description:     types.StringValue(description),
-		Password:		types.StringValue(password),
+		Username:       types.StringValue(username),
+		FolderParentId: stringValueOrNull(folderParentID),
+		Uri:            stringValueOrNull(uri),
+		Description:    stringValueOrNull(description),
+		Password:       types.StringValue(password),
+		Share:          plan.Share,
+		Timeouts:       plan.Timeouts,
 	}
 
-
+	if plan.Share != nil {
+		permissions, err := getResourcePermissions(ctx, r.client, plan.ID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to read password permissions",
+				"Could not read the permissions of password "+plan.ID.ValueString()+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+		passwordState.Share = r.refreshShares(ctx, plan.Share, permissions, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
 
 	// Set state
 	diag := resp.State.Set(ctx, passwordState)
@@ -211,6 +323,16 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	var state passwordModel
 	diags = req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -218,7 +340,9 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
-	errUpd  := helper.UpdateResource(ctx, r.client.Client, state.ID.ValueString(), plan.Name.ValueString(), plan.Username.ValueString(), plan.Uri.ValueString(), plan.Password.ValueString(), plan.Description.ValueString())
+	errUpd := r.client.Do(ctx, func() error {
+		return helper.UpdateResource(ctx, r.client.Client, state.ID.ValueString(), plan.Name.ValueString(), plan.Username.ValueString(), plan.Uri.ValueString(), plan.Password.ValueString(), plan.Description.ValueString())
+	})
 	if errUpd != nil {
 		resp.Diagnostics.AddError(
 			"Unable to update password ", "",
@@ -226,8 +350,15 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
+	r.updateShares(ctx, state, plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	if state.FolderParentId != plan.FolderParentId {
-		errMove := helper.MoveResource(ctx, r.client.Client, state.ID.ValueString(),plan.FolderParentId.ValueString())
+		errMove := r.client.Do(ctx, func() error {
+			return helper.MoveResource(ctx, r.client.Client, state.ID.ValueString(), plan.FolderParentId.ValueString())
+		})
 		if errMove != nil {
 			resp.Diagnostics.AddError(
 				"Unable to move password ", "",
@@ -239,11 +370,13 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 	passwordState := passwordModel{
 		ID:             state.ID,
 		Name:           plan.Name,
-		Username: 		plan.Username,
+		Username:       plan.Username,
 		FolderParentId: plan.FolderParentId,
-		Uri:          plan.Uri,
-		Description:     plan.Description,
-		Password:		plan.Password,
+		Uri:            plan.Uri,
+		Description:    plan.Description,
+		Password:       plan.Password,
+		Share:          plan.Share,
+		Timeouts:       plan.Timeouts,
 	}
 
 	// Set state
@@ -252,7 +385,7 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	
+
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -264,9 +397,21 @@ func (r *passwordResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	// Delete existing order
-	err := r.client.Client.DeleteResource(ctx, state.ID.ValueString())
-	if err != nil {
+	err := r.client.Do(ctx, func() error {
+		return r.client.Client.DeleteResource(ctx, state.ID.ValueString())
+	})
+	// A retried delete finds the object already gone.
+	if err != nil && !tools.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Error deleting password",
 			"Could not delete password, unexpected error: "+err.Error(),
@@ -274,3 +419,167 @@ func (r *passwordResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 }
+
+// ImportState imports an existing password by its Passbolt ID.
+func (r *passwordResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// ModifyPlan sets the principal of the planned shares given by ID, and
+// marks the others unknown as their name or email is resolved again when
+// applying.
+func (r *passwordResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing is applied when the resource is destroyed or unchanged.
+	if req.Plan.Raw.IsNull() || req.Plan.Raw.Equal(req.State.Raw) {
+		return
+	}
+
+	var planned types.Set
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("share"), &planned)...)
+	if resp.Diagnostics.HasError() || planned.IsNull() || planned.IsUnknown() {
+		return
+	}
+
+	var shares []passwordShareModel
+	resp.Diagnostics.Append(planned.ElementsAs(ctx, &shares, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for i := range shares {
+		shares[i].planPrincipal()
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("share"), shares)...)
+}
+
+// shareOperations resolves the principals of shares into share operations
+// granting their permission, and records the principals in shares.
+func (r *passwordResource) shareOperations(ctx context.Context, shares []passwordShareModel, diags *diag.Diagnostics) []helper.ShareOperation {
+	operations := make([]helper.ShareOperation, 0, len(shares))
+	seen := make(map[string]bool, len(shares))
+	for i, share := range shares {
+		aro, aroID := share.principal(ctx, r.client, diags)
+		if diags.HasError() {
+			return nil
+		}
+		if seen[aro+":"+aroID] {
+			diags.AddAttributeError(path.Root("share"), "Duplicate share", "The password is shared more than once with "+aro+" "+aroID+".")
+			return nil
+		}
+		seen[aro+":"+aroID] = true
+
+		shares[i].ARO = types.StringValue(aro)
+		shares[i].AROID = types.StringValue(aroID)
+		operations = append(operations, helper.ShareOperation{
+			Type:  permissionTypes[share.Permission.ValueString()],
+			ARO:   aro,
+			AROID: aroID,
+		})
+	}
+	return operations
+}
+
+// updateShares grants the planned shares and revokes those removed from the
+// plan, leaving other permissions of the password untouched.
+func (r *passwordResource) updateShares(ctx context.Context, state, plan passwordModel, diags *diag.Diagnostics) {
+	planned := r.shareOperations(ctx, plan.Share, diags)
+	if diags.HasError() {
+		return
+	}
+
+	current, err := getResourcePermissions(ctx, r.client, state.ID.ValueString())
+	if err != nil {
+		diags.AddError(
+			"Unable to read password permissions",
+			"Could not read the permissions of password "+state.ID.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var changes []helper.ShareOperation
+	wanted := make(map[string]bool, len(planned))
+	for _, operation := range planned {
+		wanted[operation.ARO+":"+operation.AROID] = true
+		if existing := findPermission(current, operation.ARO, operation.AROID); existing == nil || existing.Type != operation.Type {
+			changes = append(changes, operation)
+		}
+	}
+
+	// Removed shares are revoked from the principal they were made with, even
+	// if their group was renamed since.
+	for _, share := range state.Share {
+		aro, aroID := share.statePrincipal(ctx, r.client, diags)
+		if diags.HasError() {
+			return
+		}
+		if wanted[aro+":"+aroID] || findPermission(current, aro, aroID) == nil {
+			continue
+		}
+		wanted[aro+":"+aroID] = true
+		changes = append(changes, helper.ShareOperation{Type: -1, ARO: aro, AROID: aroID})
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	err = r.client.Do(ctx, func() error {
+		return helper.ShareResource(ctx, r.client.Client, state.ID.ValueString(), changes)
+	})
+	if err != nil {
+		diags.AddError(
+			"Unable to share password",
+			"Could not change the shares of password "+state.ID.ValueString()+", unexpected error: "+err.Error(),
+		)
+	}
+}
+
+// refreshShares drops the shares that were revoked outside of Terraform and
+// refreshes the permission of the others.
+func (r *passwordResource) refreshShares(ctx context.Context, shares []passwordShareModel, permissions []api.Permission, diags *diag.Diagnostics) []passwordShareModel {
+	refreshed := make([]passwordShareModel, 0, len(shares))
+	for _, share := range shares {
+		aro, aroID := share.statePrincipal(ctx, r.client, diags)
+		if diags.HasError() {
+			return nil
+		}
+
+		permission := findPermission(permissions, aro, aroID)
+		if permission == nil {
+			continue
+		}
+
+		share.Permission = types.StringValue(permissionName(permission.Type))
+		share.ARO = types.StringValue(aro)
+		share.AROID = types.StringValue(aroID)
+		refreshed = append(refreshed, share)
+	}
+	return refreshed
+}
+
+// principal resolves the ARO and ARO ID the password is shared with.
+func (m passwordShareModel) principal(ctx context.Context, client *tools.PassboltClient, diags *diag.Diagnostics) (string, string) {
+	return sharePrincipal(ctx, client, path.Root("share"), m.ShareGroupId, m.ShareUserId, m.ShareGroupName, m.ShareUserEmail, diags)
+}
+
+// statePrincipal returns the ARO and ARO ID recorded for a share in the
+// state, resolving them for shares stored before they were recorded.
+func (m passwordShareModel) statePrincipal(ctx context.Context, client *tools.PassboltClient, diags *diag.Diagnostics) (string, string) {
+	if !m.AROID.IsNull() {
+		return m.ARO.ValueString(), m.AROID.ValueString()
+	}
+	return m.principal(ctx, client, diags)
+}
+
+// planPrincipal sets the ARO and ARO ID of a planned share, which are only
+// known before applying when the share is given by ID.
+func (m *passwordShareModel) planPrincipal() {
+	switch {
+	case m.ShareGroupId.ValueString() != "":
+		m.ARO, m.AROID = types.StringValue("Group"), m.ShareGroupId
+	case m.ShareUserId.ValueString() != "":
+		m.ARO, m.AROID = types.StringValue("User"), m.ShareUserId
+	default:
+		m.ARO, m.AROID = types.StringUnknown(), types.StringUnknown()
+	}
+}
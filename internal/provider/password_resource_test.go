@@ -0,0 +1,367 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testGroup2ID = "4d9b2c3e-6f70-4a81-9b2c-1d2e3f4a5b44"
+
+// passwordShare returns a share of the password, principal is the attribute
+// giving the principal.
+func passwordShare(principal, value, permission string) passwordShareModel {
+	share := passwordShareModel{
+		ShareGroupId:   types.StringNull(),
+		ShareUserId:    types.StringNull(),
+		ShareGroupName: types.StringNull(),
+		ShareUserEmail: types.StringNull(),
+		Permission:     types.StringValue(permission),
+		ARO:            types.StringNull(),
+		AROID:          types.StringNull(),
+	}
+	switch principal {
+	case "share_group_id":
+		share.ShareGroupId = types.StringValue(value)
+	case "share_user_id":
+		share.ShareUserId = types.StringValue(value)
+	case "share_group_name":
+		share.ShareGroupName = types.StringValue(value)
+	case "share_user_email":
+		share.ShareUserEmail = types.StringValue(value)
+	}
+	return share
+}
+
+// withPrincipal records the resolved principal of a share, as in the state.
+func withPrincipal(share passwordShareModel, aro, aroID string) passwordShareModel {
+	share.ARO = types.StringValue(aro)
+	share.AROID = types.StringValue(aroID)
+	return share
+}
+
+// handlePrincipals serves the groups and users the principals of shares are
+// looked up in.
+func handlePrincipals(server *fakePassbolt) {
+	server.handle("GET /groups.json", []api.Group{{ID: testGroupID, Name: "Ops"}, {ID: testGroup2ID, Name: "Dev"}})
+	server.handle("GET /users.json", []api.User{{ID: testUser2ID, Username: "jane@example.com"}})
+	server.handle("GET /users/"+testUserID+".json", api.User{ID: testUserID, Username: "john@example.com"})
+	server.handleNotFound("GET /users/" + testUser2ID + ".json")
+}
+
+func TestShareOperations(t *testing.T) {
+	tests := []struct {
+		name    string
+		shares  []passwordShareModel
+		want    []helper.ShareOperation
+		wantErr bool
+	}{
+		{
+			name: "every principal",
+			shares: []passwordShareModel{
+				passwordShare("share_group_id", testGroupID, "read"),
+				passwordShare("share_user_id", testUserID, "update"),
+				passwordShare("share_group_name", "dev", "owner"),
+				passwordShare("share_user_email", "Jane@example.com", "read"),
+			},
+			want: []helper.ShareOperation{
+				{Type: 1, ARO: "Group", AROID: testGroupID},
+				{Type: 7, ARO: "User", AROID: testUserID},
+				{Type: 15, ARO: "Group", AROID: testGroup2ID},
+				{Type: 1, ARO: "User", AROID: testUser2ID},
+			},
+		},
+		{
+			name: "same group by ID and name",
+			shares: []passwordShareModel{
+				passwordShare("share_group_id", testGroupID, "read"),
+				passwordShare("share_group_name", "Ops", "update"),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown group",
+			shares:  []passwordShareModel{passwordShare("share_group_name", "QA", "read")},
+			wantErr: true,
+		},
+		{
+			name:    "unknown user",
+			shares:  []passwordShareModel{passwordShare("share_user_id", testUser2ID, "read")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			handlePrincipals(server)
+			r := &passwordResource{client: server.client()}
+
+			var diags diag.Diagnostics
+			got := r.shareOperations(context.Background(), tt.shares, &diags)
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("shareOperations() diagnostics = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("shareOperations() = %v, want %v", got, tt.want)
+			}
+			for i, share := range tt.shares {
+				if share.ARO.ValueString() != tt.want[i].ARO || share.AROID.ValueString() != tt.want[i].AROID {
+					t.Errorf("share %d recorded %s %s, want %s %s", i, share.ARO, share.AROID, tt.want[i].ARO, tt.want[i].AROID)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateShares(t *testing.T) {
+	current := []api.Permission{
+		{ID: "p1", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "User", AROForeignKey: testUserID, Type: 15},
+		{ID: "p2", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "Group", AROForeignKey: testGroupID, Type: 1},
+		{ID: "p3", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "Group", AROForeignKey: testGroup2ID, Type: 7},
+	}
+	// The group named Old was renamed since, its share is revoked by ID.
+	renamed := withPrincipal(passwordShare("share_group_name", "Old", "update"), "Group", testGroup2ID)
+
+	tests := []struct {
+		name       string
+		state      []passwordShareModel
+		plan       []passwordShareModel
+		groupsDown bool
+		wantShared []api.Permission
+		wantErr    bool
+	}{
+		{
+			name:  "unchanged",
+			state: []passwordShareModel{withPrincipal(passwordShare("share_group_id", testGroupID, "read"), "Group", testGroupID)},
+			plan:  []passwordShareModel{passwordShare("share_group_id", testGroupID, "read")},
+		},
+		{
+			name:  "permission changed",
+			state: []passwordShareModel{withPrincipal(passwordShare("share_group_id", testGroupID, "read"), "Group", testGroupID)},
+			plan:  []passwordShareModel{passwordShare("share_group_id", testGroupID, "update")},
+			wantShared: []api.Permission{
+				{ID: "p2", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "Group", AROForeignKey: testGroupID, Type: 7},
+			},
+		},
+		{
+			name:  "renamed group revoked",
+			state: []passwordShareModel{renamed},
+			wantShared: []api.Permission{
+				{ID: "p3", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "Group", AROForeignKey: testGroup2ID, Type: 7, Delete: true},
+			},
+		},
+		{
+			name:  "renamed group revoked while the lookup fails",
+			state: []passwordShareModel{renamed},
+			plan:  []passwordShareModel{passwordShare("share_user_id", testUserID, "owner")},
+			wantShared: []api.Permission{
+				{ID: "p3", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "Group", AROForeignKey: testGroup2ID, Type: 7, Delete: true},
+			},
+			groupsDown: true,
+		},
+		{
+			name:       "planned lookup fails",
+			state:      []passwordShareModel{renamed},
+			plan:       []passwordShareModel{passwordShare("share_group_name", "Ops", "read")},
+			groupsDown: true,
+			wantErr:    true,
+		},
+		{
+			name:  "share moved to another group",
+			state: []passwordShareModel{withPrincipal(passwordShare("share_group_id", testGroupID, "read"), "Group", testGroupID)},
+			plan:  []passwordShareModel{passwordShare("share_group_name", "Dev", "read")},
+			wantShared: []api.Permission{
+				{ID: "p3", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "Group", AROForeignKey: testGroup2ID, Type: 1},
+				{ID: "p2", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "Group", AROForeignKey: testGroupID, Type: 1, Delete: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			handlePrincipals(server)
+			if tt.groupsDown {
+				server.handleFunc("GET /groups.json", func(w http.ResponseWriter, _ *http.Request) {
+					writeAPIError(w, http.StatusInternalServerError, "Internal error.")
+				})
+			}
+			server.handlePassword(testResourceID, current)
+			r := &passwordResource{client: server.client()}
+
+			state := passwordModel{ID: types.StringValue(testResourceID), Share: tt.state}
+			plan := passwordModel{ID: types.StringValue(testResourceID), Share: tt.plan}
+
+			var diags diag.Diagnostics
+			r.updateShares(context.Background(), state, plan, &diags)
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("updateShares() diagnostics = %v, wantErr %v", diags, tt.wantErr)
+			}
+
+			shares := server.requested("PUT /share/resource/" + testResourceID + ".json")
+			if tt.wantShared == nil {
+				if len(shares) != 0 {
+					t.Fatalf("updateShares() shared %v, want no share", sharedPermissions(t, shares[0]))
+				}
+				return
+			}
+			if len(shares) != 1 {
+				t.Fatalf("updateShares() shared %d times, want once", len(shares))
+			}
+			assertPermissions(t, sharedPermissions(t, shares[0]), tt.wantShared)
+		})
+	}
+}
+
+func TestRefreshShares(t *testing.T) {
+	permissions := []api.Permission{
+		{ARO: "User", AROForeignKey: testUserID, Type: 15},
+		{ARO: "Group", AROForeignKey: testGroupID, Type: 7},
+	}
+
+	tests := []struct {
+		name       string
+		shares     []passwordShareModel
+		groupsDown bool
+		want       []passwordShareModel
+		wantErr    bool
+	}{
+		{
+			name: "stored principals",
+			shares: []passwordShareModel{
+				withPrincipal(passwordShare("share_group_name", "Renamed", "read"), "Group", testGroupID),
+				withPrincipal(passwordShare("share_user_email", "john@example.com", "owner"), "User", testUserID),
+			},
+			// The stored principals are used, so failing lookups do not matter.
+			groupsDown: true,
+			want: []passwordShareModel{
+				withPrincipal(passwordShare("share_group_name", "Renamed", "update"), "Group", testGroupID),
+				withPrincipal(passwordShare("share_user_email", "john@example.com", "owner"), "User", testUserID),
+			},
+		},
+		{
+			name: "revoked share dropped",
+			shares: []passwordShareModel{
+				withPrincipal(passwordShare("share_group_id", testGroup2ID, "read"), "Group", testGroup2ID),
+				withPrincipal(passwordShare("share_user_id", testUserID, "owner"), "User", testUserID),
+			},
+			want: []passwordShareModel{
+				withPrincipal(passwordShare("share_user_id", testUserID, "owner"), "User", testUserID),
+			},
+		},
+		{
+			name:   "principal stored before it was recorded",
+			shares: []passwordShareModel{passwordShare("share_group_name", "Ops", "update")},
+			want: []passwordShareModel{
+				withPrincipal(passwordShare("share_group_name", "Ops", "update"), "Group", testGroupID),
+			},
+		},
+		{
+			name:       "failed lookup reported",
+			shares:     []passwordShareModel{passwordShare("share_group_name", "Ops", "update")},
+			groupsDown: true,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			handlePrincipals(server)
+			if tt.groupsDown {
+				server.handleFunc("GET /groups.json", func(w http.ResponseWriter, _ *http.Request) {
+					writeAPIError(w, http.StatusInternalServerError, "Internal error.")
+				})
+			}
+			r := &passwordResource{client: server.client()}
+
+			var diags diag.Diagnostics
+			got := r.refreshShares(context.Background(), tt.shares, permissions, &diags)
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("refreshShares() diagnostics = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("refreshShares() = %v, want %v", got, tt.want)
+			}
+			if tt.groupsDown && len(server.requested("GET /groups.json")) != 0 {
+				t.Errorf("refreshShares() looked up groups for stored principals")
+			}
+		})
+	}
+}
+
+func TestPasswordRead(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		wantRemoved bool
+		wantDetail  string
+	}{
+		{
+			name: "deleted",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				writeAPIError(w, http.StatusNotFound, "The resource does not exist.")
+			},
+			wantRemoved: true,
+		},
+		{
+			name: "error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				writeAPIError(w, http.StatusInternalServerError, "Internal error.")
+			},
+			wantDetail: "Internal error.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			server.handleFunc("GET /resources/"+testResourceID+".json", tt.handler)
+			r := &passwordResource{client: server.client()}
+
+			state := stateOf(t, r, passwordModel{ID: types.StringValue(testResourceID), Timeouts: nullTimeouts()})
+			resp := resource.ReadResponse{State: state}
+			r.Read(context.Background(), resource.ReadRequest{State: state}, &resp)
+
+			assertReadResult(t, resp, tt.wantRemoved, tt.wantDetail)
+		})
+	}
+}
+
+// assertReadResult checks that Read removed the resource from the state, or
+// reported an error with wantDetail in its detail.
+func assertReadResult(t *testing.T, resp resource.ReadResponse, wantRemoved bool, wantDetail string) {
+	t.Helper()
+	if wantRemoved {
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("Read() diagnostics = %v, want none", resp.Diagnostics)
+		}
+		if !resp.State.Raw.IsNull() {
+			t.Errorf("Read() kept the resource in the state")
+		}
+		return
+	}
+	if !resp.Diagnostics.HasError() {
+		t.Fatalf("Read() reported no error")
+	}
+	if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, wantDetail) {
+		t.Errorf("Read() error detail = %q, want it to contain %q", detail, wantDetail)
+	}
+	if resp.State.Raw.IsNull() {
+		t.Errorf("Read() removed the resource from the state")
+	}
+}
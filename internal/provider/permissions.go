@@ -0,0 +1,254 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+	"strings"
+	"terraform-provider-passbolt/tools"
+)
+
+// permissionTypes maps the permission names used in the schema to the
+// Passbolt permission types.
+var permissionTypes = map[string]int{
+	"read":   1,
+	"update": 7,
+	"owner":  15,
+}
+
+// permissionNames lists the accepted permission names.
+var permissionNames = []string{"read", "update", "owner"}
+
+// permissionName returns the schema name of a Passbolt permission type.
+func permissionName(permissionType int) string {
+	for name, t := range permissionTypes {
+		if t == permissionType {
+			return name
+		}
+	}
+	return fmt.Sprint(permissionType)
+}
+
+// sharePermissionType returns the Passbolt permission type of a share,
+// falling back to the deprecated modify attribute when permission is not
+// set.
+func sharePermissionType(permission types.String, modify types.Bool) int {
+	if t, ok := permissionTypes[permission.ValueString()]; ok {
+		return t
+	}
+	return TypePerm(modify.ValueBool())
+}
+
+// permissionModel is a permission managed authoritatively on a password or
+// folder.
+type permissionModel struct {
+	ARO   types.String `tfsdk:"aro"`
+	AROID types.String `tfsdk:"aro_id"`
+	Type  types.String `tfsdk:"type"`
+}
+
+// permissionsAttribute returns the schema of the set of permissions managed
+// on the aco, "password" or "folder".
+func permissionsAttribute(aco string) schema.SetNestedAttribute {
+	return schema.SetNestedAttribute{
+		Description: "All permissions on the " + aco + ", permissions not listed are removed. At least one must be of type owner.",
+		Required:    true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"aro": schema.StringAttribute{
+					Description: "Kind of principal, Group or User.",
+					Required:    true,
+					Validators: []validator.String{
+						stringvalidator.OneOf("Group", "User"),
+					},
+				},
+				"aro_id": schema.StringAttribute{
+					Description: "ID of the group or user.",
+					Required:    true,
+				},
+				"type": schema.StringAttribute{
+					Description: "Permission granted, one of read, update or owner.",
+					Required:    true,
+					Validators: []validator.String{
+						stringvalidator.OneOf(permissionNames...),
+					},
+				},
+			},
+		},
+	}
+}
+
+// permissionModels converts Passbolt permissions to their schema
+// representation.
+func permissionModels(permissions []api.Permission) []permissionModel {
+	models := make([]permissionModel, 0, len(permissions))
+	for _, permission := range permissions {
+		models = append(models, permissionModel{
+			ARO:   types.StringValue(permission.ARO),
+			AROID: types.StringValue(permission.AROForeignKey),
+			Type:  types.StringValue(permissionName(permission.Type)),
+		})
+	}
+	return models
+}
+
+// permissionChanges returns the share operations turning the current
+// permissions into the desired ones, removing those not desired. Passbolt
+// requires an owner, so desired must contain at least one.
+func permissionChanges(current []api.Permission, desired []permissionModel) ([]helper.ShareOperation, error) {
+	var changes []helper.ShareOperation
+	wanted := make(map[string]bool, len(desired))
+	owners := 0
+
+	for _, permission := range desired {
+		aro, aroID := permission.ARO.ValueString(), permission.AROID.ValueString()
+		key := aro + ":" + aroID
+		if wanted[key] {
+			return nil, fmt.Errorf("%s %s is listed more than once", aro, aroID)
+		}
+		wanted[key] = true
+
+		permissionType := permissionTypes[permission.Type.ValueString()]
+		if permissionType == permissionTypes["owner"] {
+			owners++
+		}
+
+		if existing := findPermission(current, aro, aroID); existing == nil || existing.Type != permissionType {
+			changes = append(changes, helper.ShareOperation{Type: permissionType, ARO: aro, AROID: aroID})
+		}
+	}
+
+	if owners == 0 {
+		return nil, errors.New("at least one permission must be of type owner, refusing to remove the last owner")
+	}
+
+	for _, permission := range current {
+		if !wanted[permission.ARO+":"+permission.AROForeignKey] {
+			changes = append(changes, helper.ShareOperation{Type: -1, ARO: permission.ARO, AROID: permission.AROForeignKey})
+		}
+	}
+
+	return changes, nil
+}
+
+// setPermissions makes the permissions of a password or folder, as given by
+// aco, match desired.
+func setPermissions(ctx context.Context, client *tools.PassboltClient, aco, acoID string, desired []permissionModel, diags *diag.Diagnostics) {
+	getPermissions := getResourcePermissions
+	if aco == "folder" {
+		getPermissions = getFolderPermissions
+	}
+
+	current, err := getPermissions(ctx, client, acoID)
+	if err != nil {
+		diags.AddError(
+			"Unable to read "+aco+" permissions",
+			"Could not read the permissions of "+aco+" "+acoID+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	changes, err := permissionChanges(current, desired)
+	if err != nil {
+		diags.AddAttributeError(path.Root("permissions"), "Invalid permissions", err.Error())
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	tflog.Debug(ctx, "Changing permissions", map[string]interface{}{aco + "_id": acoID, "changes": len(changes)})
+
+	err = client.Do(ctx, func() error {
+		if aco == "folder" {
+			return helper.ShareFolder(ctx, client.Client, acoID, changes)
+		}
+		return helper.ShareResource(ctx, client.Client, acoID, changes)
+	})
+	if err != nil {
+		diags.AddError(
+			"Unable to change "+aco+" permissions",
+			"Could not change the permissions of "+aco+" "+acoID+", unexpected error: "+err.Error(),
+		)
+	}
+}
+
+// getResourcePermissions returns the permissions of a password.
+func getResourcePermissions(ctx context.Context, client *tools.PassboltClient, resourceID string) ([]api.Permission, error) {
+	var permissions []api.Permission
+	err := client.Do(ctx, func() (err error) {
+		permissions, err = client.Client.GetResourcePermissions(ctx, resourceID)
+		return err
+	})
+	return permissions, err
+}
+
+// getFolderPermissions returns the permissions of a folder.
+func getFolderPermissions(ctx context.Context, client *tools.PassboltClient, folderID string) ([]api.Permission, error) {
+	var folder *api.Folder
+	err := client.Do(ctx, func() (err error) {
+		folder, err = client.Client.GetFolder(ctx, folderID, &api.GetFolderOptions{ContainPermissions: true})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return folder.Permissions, nil
+}
+
+// findPermission returns the permission granted to the given ARO, or nil.
+func findPermission(permissions []api.Permission, aro, aroID string) *api.Permission {
+	for i := range permissions {
+		if permissions[i].ARO == aro && permissions[i].AROForeignKey == aroID {
+			return &permissions[i]
+		}
+	}
+	return nil
+}
+
+// shareID returns the ID of a share, "<aco_id>:<aro>:<aro_id>" where aco_id
+// is the ID of the shared password or folder.
+func shareID(acoID, aro, aroID string) string {
+	return acoID + ":" + aro + ":" + aroID
+}
+
+// parseShareImportID accepts either "<aco_id>/<group_id>" or a share ID as
+// returned by shareID.
+func parseShareImportID(id, format string) (acoID, aro, aroID string, err error) {
+	if parts := strings.Split(id, ":"); len(parts) == 3 {
+		if parts[0] == "" || (parts[1] != "Group" && parts[1] != "User") || parts[2] == "" {
+			return "", "", "", fmt.Errorf("expected import ID in the format %s or <id>:<Group|User>:<aro_id>, got %q", format, id)
+		}
+		return parts[0], parts[1], parts[2], nil
+	}
+
+	acoID, aroID, err = splitImportID(id, format)
+	return acoID, "Group", aroID, err
+}
+
+// shareIDPrincipal returns the ARO and ARO ID of a share from its ID.
+func shareIDPrincipal(id types.String) (string, string) {
+	parts := strings.Split(id.ValueString(), ":")
+	if len(parts) != 3 {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
+
+// splitImportID splits a composite import ID of the form "first/second".
+func splitImportID(id, format string) (string, string, error) {
+	first, second, found := strings.Cut(id, "/")
+	if !found || first == "" || second == "" || strings.Contains(second, "/") {
+		return "", "", fmt.Errorf("expected import ID in the format %s, got %q", format, id)
+	}
+	return first, second, nil
+}
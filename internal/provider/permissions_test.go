@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+	"reflect"
+	"testing"
+)
+
+func permission(aro, aroID, permissionType string) permissionModel {
+	return permissionModel{
+		ARO:   types.StringValue(aro),
+		AROID: types.StringValue(aroID),
+		Type:  types.StringValue(permissionType),
+	}
+}
+
+func TestPermissionChanges(t *testing.T) {
+	current := []api.Permission{
+		{ARO: "User", AROForeignKey: "u1", Type: 15},
+		{ARO: "Group", AROForeignKey: "g1", Type: 1},
+	}
+
+	tests := []struct {
+		name    string
+		current []api.Permission
+		desired []permissionModel
+		want    []helper.ShareOperation
+		wantErr bool
+	}{
+		{
+			name:    "unchanged",
+			current: current,
+			desired: []permissionModel{permission("User", "u1", "owner"), permission("Group", "g1", "read")},
+		},
+		{
+			name:    "type changed",
+			current: current,
+			desired: []permissionModel{permission("User", "u1", "owner"), permission("Group", "g1", "update")},
+			want:    []helper.ShareOperation{{Type: 7, ARO: "Group", AROID: "g1"}},
+		},
+		{
+			name:    "added",
+			current: current,
+			desired: []permissionModel{permission("User", "u1", "owner"), permission("Group", "g1", "read"), permission("User", "u2", "read")},
+			want:    []helper.ShareOperation{{Type: 1, ARO: "User", AROID: "u2"}},
+		},
+		{
+			name:    "removed",
+			current: current,
+			desired: []permissionModel{permission("User", "u1", "owner")},
+			want:    []helper.ShareOperation{{Type: -1, ARO: "Group", AROID: "g1"}},
+		},
+		{
+			name:    "owner replaced",
+			current: current,
+			desired: []permissionModel{permission("Group", "g1", "owner")},
+			want: []helper.ShareOperation{
+				{Type: 15, ARO: "Group", AROID: "g1"},
+				{Type: -1, ARO: "User", AROID: "u1"},
+			},
+		},
+		{
+			name:    "last owner demoted",
+			current: current,
+			desired: []permissionModel{permission("User", "u1", "update"), permission("Group", "g1", "read")},
+			wantErr: true,
+		},
+		{
+			name:    "last owner removed",
+			current: current,
+			desired: []permissionModel{permission("Group", "g1", "read")},
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			current: current,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate",
+			current: current,
+			desired: []permissionModel{permission("User", "u1", "owner"), permission("User", "u1", "read")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := permissionChanges(tt.current, tt.desired)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("permissionChanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("permissionChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseShareImportID(t *testing.T) {
+	tests := []struct {
+		id        string
+		wantACOID string
+		wantARO   string
+		wantAROID string
+		wantErr   bool
+	}{
+		{id: "r1/g1", wantACOID: "r1", wantARO: "Group", wantAROID: "g1"},
+		{id: "r1:Group:g1", wantACOID: "r1", wantARO: "Group", wantAROID: "g1"},
+		{id: "r1:User:u1", wantACOID: "r1", wantARO: "User", wantAROID: "u1"},
+		{id: "r1:Role:u1", wantErr: true},
+		{id: ":User:u1", wantErr: true},
+		{id: "r1:User:", wantErr: true},
+		{id: "r1", wantErr: true},
+		{id: "r1/", wantErr: true},
+		{id: "/g1", wantErr: true},
+		{id: "r1/g1/x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			acoID, aro, aroID, err := parseShareImportID(tt.id, "resource_id/group_id")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseShareImportID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if acoID != tt.wantACOID || aro != tt.wantARO || aroID != tt.wantAROID {
+				t.Errorf("parseShareImportID() = %q, %q, %q, want %q, %q, %q", acoID, aro, aroID, tt.wantACOID, tt.wantARO, tt.wantAROID)
+			}
+		})
+	}
+}
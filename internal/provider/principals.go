@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/passbolt/go-passbolt/api"
+	"strings"
+	"terraform-provider-passbolt/tools"
+)
+
+// sharePrincipal resolves the ARO and ARO ID a share is made with from the
+// share_* attributes below parent, exactly one of which is expected to be
+// set. Names and emails are looked up, user IDs are checked to exist.
+func sharePrincipal(ctx context.Context, client *tools.PassboltClient, parent path.Path,
+	groupID, userID, groupName, userEmail types.String, diags *diag.Diagnostics) (string, string) {
+	switch {
+	case groupName.ValueString() != "":
+		return "Group", resolveGroupName(ctx, client, parent.AtName("share_group_name"), groupName.ValueString(), diags)
+	case userEmail.ValueString() != "":
+		return "User", resolveUserEmail(ctx, client, parent.AtName("share_user_email"), userEmail.ValueString(), diags)
+	case userID.ValueString() != "":
+		checkUserID(ctx, client, parent.AtName("share_user_id"), userID.ValueString(), diags)
+		return "User", userID.ValueString()
+	}
+	return "Group", groupID.ValueString()
+}
+
+// resolveGroupName returns the ID of the group called name. Exact matches are
+// preferred over case insensitive ones.
+func resolveGroupName(ctx context.Context, client *tools.PassboltClient, attribute path.Path, name string, diags *diag.Diagnostics) string {
+	var groups []api.Group
+	err := client.Do(ctx, func() (err error) {
+		groups, err = client.Client.GetGroups(ctx, nil)
+		return err
+	})
+	if err != nil {
+		diags.AddError("Unable to read groups", "Could not list the groups to find "+name+", unexpected error: "+err.Error())
+		return ""
+	}
+
+	var exact, folded []string
+	for _, group := range groups {
+		if group.Name == name {
+			exact = append(exact, group.ID)
+		} else if strings.EqualFold(group.Name, name) {
+			folded = append(folded, group.ID)
+		}
+	}
+	if len(exact) == 0 {
+		exact = folded
+	}
+
+	switch len(exact) {
+	case 0:
+		diags.AddAttributeError(attribute, "Group not found", "No Passbolt group is named "+name+".")
+		return ""
+	case 1:
+		tflog.Debug(ctx, "Resolved group name", map[string]interface{}{"name": name, "group_id": exact[0]})
+		return exact[0]
+	default:
+		diags.AddAttributeError(
+			attribute,
+			"Ambiguous group name",
+			fmt.Sprintf("%d Passbolt groups are named %s: %s. Use the group ID instead.", len(exact), name, strings.Join(exact, ", ")),
+		)
+		return ""
+	}
+}
+
+// resolveUserEmail returns the ID of the user whose username is email.
+func resolveUserEmail(ctx context.Context, client *tools.PassboltClient, attribute path.Path, email string, diags *diag.Diagnostics) string {
+	var users []api.User
+	err := client.Do(ctx, func() (err error) {
+		users, err = client.Client.GetUsers(ctx, &api.GetUsersOptions{FilterSearch: email})
+		return err
+	})
+	if err != nil {
+		diags.AddError("Unable to read users", "Could not search the users to find "+email+", unexpected error: "+err.Error())
+		return ""
+	}
+
+	var ids []string
+	for _, user := range users {
+		if strings.EqualFold(user.Username, email) {
+			ids = append(ids, user.ID)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		diags.AddAttributeError(attribute, "User not found", "No Passbolt user has the email "+email+".")
+		return ""
+	case 1:
+		tflog.Debug(ctx, "Resolved user email", map[string]interface{}{"email": email, "user_id": ids[0]})
+		return ids[0]
+	default:
+		diags.AddAttributeError(
+			attribute,
+			"Ambiguous user email",
+			fmt.Sprintf("%d Passbolt users have the email %s: %s. Use the user ID instead.", len(ids), email, strings.Join(ids, ", ")),
+		)
+		return ""
+	}
+}
+
+// checkUserID checks that the user exists, as Passbolt only reports an
+// unknown user once a share is simulated.
+func checkUserID(ctx context.Context, client *tools.PassboltClient, attribute path.Path, userID string, diags *diag.Diagnostics) {
+	var user *api.User
+	err := client.Do(ctx, func() (err error) {
+		user, err = client.Client.GetUser(ctx, userID)
+		return err
+	})
+	if tools.IsNotFound(err) {
+		diags.AddAttributeError(attribute, "User not found", "No Passbolt user has the ID "+userID+".")
+		return
+	}
+	if err != nil {
+		diags.AddError("Unable to read user", "Could not read user "+userID+", unexpected error: "+err.Error())
+		return
+	}
+
+	tflog.Debug(ctx, "Resolved share user", map[string]interface{}{"user_id": userID, "username": user.Username})
+}
@@ -2,17 +2,33 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"terraform-provider-passbolt/tools"
+	"time"
 )
 
+// fingerprintPattern matches a normalized v4 or v5 GPG key fingerprint.
+var fingerprintPattern = regexp.MustCompile(`^([0-9A-F]{40}|[0-9A-F]{64})$`)
+
+// defaultTimeout applies to resource operations without a configured timeout.
+const defaultTimeout = 20 * time.Minute
+
 // Ensure the implementation satisfies the expected interfaces.
 var (
 	_ provider.Provider = &passboltProvider{}
@@ -36,9 +52,39 @@ type passboltProvider struct {
 }
 
 type hashicupsProviderModel struct {
-	URL  types.String `tfsdk:"base_url"`
-	KEY  types.String `tfsdk:"private_key"`
-	PASS types.String `tfsdk:"passphrase"`
+	URL       types.String `tfsdk:"base_url"`
+	KEY       types.String `tfsdk:"private_key"`
+	KeyFile   types.String `tfsdk:"private_key_file"`
+	KeyBase64 types.String `tfsdk:"private_key_base64"`
+	PASS      types.String `tfsdk:"passphrase"`
+	MFA       *mfaModel    `tfsdk:"mfa"`
+
+	CACertFile         types.String `tfsdk:"ca_cert_file"`
+	CACertPEM          types.String `tfsdk:"ca_cert_pem"`
+	ClientCert         types.String `tfsdk:"client_cert"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	TLSServerName      types.String `tfsdk:"tls_server_name"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+
+	ProxyURL types.String `tfsdk:"proxy_url"`
+	NoProxy  types.String `tfsdk:"no_proxy"`
+	Headers  types.Map    `tfsdk:"headers"`
+
+	MaxRetries   types.Int64  `tfsdk:"max_retries"`
+	RetryMaxWait types.String `tfsdk:"retry_max_wait"`
+
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+
+	ServerKeyFingerprint types.String `tfsdk:"server_key_fingerprint"`
+
+	Profile      types.String `tfsdk:"profile"`
+	ProfilesFile types.String `tfsdk:"profiles_file"`
+}
+
+type mfaModel struct {
+	TOTPSecret types.String `tfsdk:"totp_secret"`
+	TOTPCode   types.String `tfsdk:"totp_code"`
 }
 
 // Metadata returns the provider type name.
@@ -50,17 +96,137 @@ func (p *passboltProvider) Metadata(_ context.Context, _ provider.MetadataReques
 // Schema defines the provider-level schema for configuration data.
 func (p *passboltProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Description: "Interact with Passbolt. Every attribute may be omitted and read from its environment variable " +
+			"instead; a value set in the provider block always takes precedence over the environment. " +
+			"Credentials from a selected profile take precedence over the environment, but not over the provider block.",
 		Attributes: map[string]schema.Attribute{
 			"base_url": schema.StringAttribute{
-				Required: true,
+				Description: "URL of the Passbolt instance. May also be provided via the PASSBOLT_URL environment variable.",
+				Optional:    true,
 			},
 			"private_key": schema.StringAttribute{
-				Required: true,
+				Description: "ASCII armored private GPG key of the Passbolt user. May also be provided via the PASSBOLT_KEY environment variable. " +
+					"Conflicts with private_key_file and private_key_base64.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"private_key_file": schema.StringAttribute{
+				Description: "Path to a file containing the ASCII armored private GPG key. May also be provided via the PASSBOLT_KEY_FILE environment variable. " +
+					"Conflicts with private_key and private_key_base64.",
+				Optional: true,
+			},
+			"private_key_base64": schema.StringAttribute{
+				Description: "Base64 encoded ASCII armored private GPG key. May also be provided via the PASSBOLT_KEY_BASE64 environment variable. " +
+					"Conflicts with private_key and private_key_file.",
+				Optional:  true,
+				Sensitive: true,
 			},
 			"passphrase": schema.StringAttribute{
-				Required:  true,
+				Description: "Passphrase of the private key. May also be provided via the PASSBOLT_PASS environment variable.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Description: "Path to a PEM file with additional certificate authorities to trust. May also be provided via the PASSBOLT_CA_CERT_FILE environment variable.",
+				Optional:    true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				Description: "PEM encoded additional certificate authorities to trust. May also be provided via the PASSBOLT_CA_CERT_PEM environment variable.",
+				Optional:    true,
+			},
+			"client_cert": schema.StringAttribute{
+				Description: "PEM encoded client certificate, or the path to it, for mutual TLS. May also be provided via the PASSBOLT_CLIENT_CERT environment variable. " +
+					"Requires client_key.",
+				Optional: true,
+			},
+			"client_key": schema.StringAttribute{
+				Description: "PEM encoded client certificate key, or the path to it, for mutual TLS. May also be provided via the PASSBOLT_CLIENT_KEY environment variable. " +
+					"Requires client_cert.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"tls_server_name": schema.StringAttribute{
+				Description: "Server name used to verify the certificate of the Passbolt server. May also be provided via the PASSBOLT_TLS_SERVER_NAME environment variable.",
+				Optional:    true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Description: "Disables verification of the Passbolt server certificate. Only use this for testing. " +
+					"May also be provided via the PASSBOLT_INSECURE_SKIP_VERIFY environment variable.",
+				Optional: true,
+			},
+			"proxy_url": schema.StringAttribute{
+				Description: "URL of the HTTP proxy used to reach Passbolt, credentials may be passed as user info. " +
+					"May also be provided via the PASSBOLT_PROXY_URL environment variable. Defaults to the HTTP_PROXY and HTTPS_PROXY environment variables.",
+				Optional:  true,
 				Sensitive: true,
 			},
+			"no_proxy": schema.StringAttribute{
+				Description: "Comma separated list of hosts that bypass the proxy, from proxy_url or the HTTP_PROXY and HTTPS_PROXY environment variables. May also be provided via the PASSBOLT_NO_PROXY environment variable.",
+				Optional:    true,
+			},
+			"headers": schema.MapAttribute{
+				Description: "Additional HTTP headers sent with every request. May also be provided via the PASSBOLT_HEADERS environment variable " +
+					"as a comma separated list of name=value pairs.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"max_retries": schema.Int64Attribute{
+				Description: "Number of times a request failing with a connection error, 429 or 5xx response is retried. " +
+					"Requests that are not idempotent are only retried on 429. Set to 0 to disable retries. " +
+					"May also be provided via the PASSBOLT_MAX_RETRIES environment variable. Defaults to 3.",
+				Optional: true,
+			},
+			"retry_max_wait": schema.StringAttribute{
+				Description: "Maximum delay between two attempts as a duration, for example \"30s\". Also caps delays requested by the server " +
+					"through Retry-After. May also be provided via the PASSBOLT_RETRY_MAX_WAIT environment variable. Defaults to 30s.",
+				Optional: true,
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Description: "Maximum number of requests per second sent to Passbolt by all resources and data sources. " +
+					"May also be provided via the PASSBOLT_REQUESTS_PER_SECOND environment variable. Unlimited by default.",
+				Optional: true,
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Description: "Maximum number of requests to Passbolt in flight at the same time. " +
+					"May also be provided via the PASSBOLT_MAX_CONCURRENT_REQUESTS environment variable. Unlimited by default.",
+				Optional: true,
+			},
+			"profile": schema.StringAttribute{
+				Description: "Name of the credential profile to read base_url, the private key, passphrase and TOTP secret from. " +
+					"May also be provided via the PASSBOLT_PROFILE environment variable.",
+				Optional: true,
+			},
+			"profiles_file": schema.StringAttribute{
+				Description: "Path to the TOML file holding the credential profiles. May also be provided via the PASSBOLT_PROFILES_FILE " +
+					"environment variable. Defaults to terraform-provider-passbolt/profiles.toml in the user configuration directory.",
+				Optional: true,
+			},
+			"server_key_fingerprint": schema.StringAttribute{
+				Description: "Fingerprint of the GPG key of the Passbolt server. When set, the key published by the server is verified " +
+					"against it before logging in. May also be provided via the PASSBOLT_SERVER_KEY_FINGERPRINT environment variable.",
+				Optional: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"mfa": schema.SingleNestedBlock{
+				Description: "Answers TOTP multi-factor authentication challenges of the Passbolt server.",
+				Attributes: map[string]schema.Attribute{
+					"totp_secret": schema.StringAttribute{
+						Description: "Base32 encoded TOTP seed used to generate codes. May also be provided via the PASSBOLT_MFA_TOTP_SECRET environment variable. " +
+							"Conflicts with totp_code.",
+						Optional:  true,
+						Sensitive: true,
+					},
+					"totp_code": schema.StringAttribute{
+						Description: "Static TOTP code, only valid for a single challenge. A re-login after the session expired " +
+							"fails if the server asks for MFA again, use totp_secret for long runs. May also be provided via the PASSBOLT_MFA_TOTP_CODE environment variable. " +
+							"Conflicts with totp_secret.",
+						Optional:  true,
+						Sensitive: true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -79,25 +245,88 @@ func (p *passboltProvider) Configure(ctx context.Context, req provider.Configure
 
 	if config.URL.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("url"),
-			"Unknown URL",
-			"",
+			path.Root("base_url"),
+			"Unknown Passbolt URL",
+			"The provider cannot create the Passbolt client as there is an unknown configuration value for the Passbolt URL. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the PASSBOLT_URL environment variable.",
 		)
 	}
 
 	if config.KEY.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("private_key"),
-			"Unknown private key",
-			"",
+			"Unknown Passbolt private key",
+			"The provider cannot create the Passbolt client as there is an unknown configuration value for the private key. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the PASSBOLT_KEY environment variable.",
+		)
+	}
+
+	if config.KeyFile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("private_key_file"),
+			"Unknown Passbolt private key file",
+			"The provider cannot create the Passbolt client as there is an unknown configuration value for the private key file. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the PASSBOLT_KEY_FILE environment variable.",
 		)
 	}
 
+	if config.KeyBase64.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("private_key_base64"),
+			"Unknown Passbolt base64 private key",
+			"The provider cannot create the Passbolt client as there is an unknown configuration value for the base64 encoded private key. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the PASSBOLT_KEY_BASE64 environment variable.",
+		)
+	}
+
+	if config.MFA != nil && (config.MFA.TOTPSecret.IsUnknown() || config.MFA.TOTPCode.IsUnknown()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("mfa"),
+			"Unknown Passbolt MFA configuration",
+			"The provider cannot create the Passbolt client as there is an unknown configuration value for the MFA settings. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the "+
+				"PASSBOLT_MFA_TOTP_SECRET or PASSBOLT_MFA_TOTP_CODE environment variable.",
+		)
+	}
+
+	for _, attribute := range []struct {
+		name    string
+		env     string
+		unknown bool
+	}{
+		{"ca_cert_file", "PASSBOLT_CA_CERT_FILE", config.CACertFile.IsUnknown()},
+		{"ca_cert_pem", "PASSBOLT_CA_CERT_PEM", config.CACertPEM.IsUnknown()},
+		{"client_cert", "PASSBOLT_CLIENT_CERT", config.ClientCert.IsUnknown()},
+		{"client_key", "PASSBOLT_CLIENT_KEY", config.ClientKey.IsUnknown()},
+		{"tls_server_name", "PASSBOLT_TLS_SERVER_NAME", config.TLSServerName.IsUnknown()},
+		{"insecure_skip_verify", "PASSBOLT_INSECURE_SKIP_VERIFY", config.InsecureSkipVerify.IsUnknown()},
+		{"proxy_url", "PASSBOLT_PROXY_URL", config.ProxyURL.IsUnknown()},
+		{"no_proxy", "PASSBOLT_NO_PROXY", config.NoProxy.IsUnknown()},
+		{"headers", "PASSBOLT_HEADERS", config.Headers.IsUnknown()},
+		{"max_retries", "PASSBOLT_MAX_RETRIES", config.MaxRetries.IsUnknown()},
+		{"retry_max_wait", "PASSBOLT_RETRY_MAX_WAIT", config.RetryMaxWait.IsUnknown()},
+		{"requests_per_second", "PASSBOLT_REQUESTS_PER_SECOND", config.RequestsPerSecond.IsUnknown()},
+		{"max_concurrent_requests", "PASSBOLT_MAX_CONCURRENT_REQUESTS", config.MaxConcurrentRequests.IsUnknown()},
+		{"server_key_fingerprint", "PASSBOLT_SERVER_KEY_FINGERPRINT", config.ServerKeyFingerprint.IsUnknown()},
+		{"profile", "PASSBOLT_PROFILE", config.Profile.IsUnknown()},
+		{"profiles_file", "PASSBOLT_PROFILES_FILE", config.ProfilesFile.IsUnknown()},
+	} {
+		if attribute.unknown {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attribute.name),
+				"Unknown Passbolt "+attribute.name,
+				"The provider cannot create the Passbolt client as there is an unknown configuration value for "+attribute.name+". "+
+					"Either target apply the source of the value first, set the value statically in the configuration, or use the "+attribute.env+" environment variable.",
+			)
+		}
+	}
+
 	if config.PASS.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("password"),
-			"Unknown password",
-			"",
+			path.Root("passphrase"),
+			"Unknown Passbolt passphrase",
+			"The provider cannot create the Passbolt client as there is an unknown configuration value for the passphrase. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the PASSBOLT_PASS environment variable.",
 		)
 	}
 
@@ -105,78 +334,177 @@ func (p *passboltProvider) Configure(ctx context.Context, req provider.Configure
 		return
 	}
 
+	// Values of a selected profile fill in the attributes missing from the
+	// configuration, before falling back to environment variables.
+
+	profile := stringValueOrEnv(config.Profile, "PASSBOLT_PROFILE")
+	if profile != "" {
+		resp.Diagnostics.Append(applyProfile(&config, profile)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		ctx = tflog.SetField(ctx, "passbolt_profile", profile)
+	}
+
 	// Default values to environment variables, but override
 	// with Terraform configuration value if set.
 
 	url := os.Getenv("PASSBOLT_URL")
-	key := os.Getenv("PASSBOLT_KEY")
 	pass := os.Getenv("PASSBOLT_PASS")
 
 	if !config.URL.IsNull() {
 		url = config.URL.ValueString()
 	}
 
-	if !config.KEY.IsNull() {
-		key = config.KEY.ValueString()
-	}
-
 	if !config.PASS.IsNull() {
 		pass = config.PASS.ValueString()
 	}
 
+	key, keyDiags := loadPrivateKey(config)
+	resp.Diagnostics.Append(keyDiags...)
+
+	mfa, mfaDiags := loadMFA(config)
+	resp.Diagnostics.Append(mfaDiags...)
+
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
 	if url == "" {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("url"),
-			"Missing url",
-			"",
+			path.Root("base_url"),
+			"Missing Passbolt URL",
+			"The provider cannot create the Passbolt client as there is a missing or empty value for the Passbolt URL. "+
+				"Set the base_url value in the configuration or use the PASSBOLT_URL environment variable. "+
+				"If either is already set, ensure the value is not empty.",
 		)
 	}
 
-	if key == "" {
+	if key == "" && !keyDiags.HasError() {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("key"),
-			"Missing private key",
-			"",
+			path.Root("private_key"),
+			"Missing Passbolt private key",
+			"The provider cannot create the Passbolt client as there is a missing or empty value for the private key. "+
+				"Set one of private_key, private_key_file or private_key_base64 in the configuration or use the "+
+				"PASSBOLT_KEY, PASSBOLT_KEY_FILE or PASSBOLT_KEY_BASE64 environment variable. "+
+				"If either is already set, ensure the value is not empty.",
 		)
 	}
 
 	if pass == "" {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("password"),
-			"Missing password",
-			"",
+			path.Root("passphrase"),
+			"Missing Passbolt passphrase",
+			"The provider cannot create the Passbolt client as there is a missing or empty value for the passphrase. "+
+				"Set the passphrase value in the configuration or use the PASSBOLT_PASS environment variable. "+
+				"If either is already set, ensure the value is not empty.",
+		)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keyInfo, err := tools.InspectPrivateKey(key, pass)
+	resp.Diagnostics.Append(privateKeyDiagnostics(keyInfo, err, time.Now())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "Using Passbolt private key", map[string]interface{}{
+		"fingerprint": keyInfo.Fingerprint,
+		"user_id":     keyInfo.UserID,
+		"algorithm":   keyInfo.Algorithm,
+	})
+
+	fingerprint := tools.NormalizeFingerprint(stringValueOrEnv(config.ServerKeyFingerprint, "PASSBOLT_SERVER_KEY_FINGERPRINT"))
+	if fingerprint != "" && !fingerprintPattern.MatchString(fingerprint) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("server_key_fingerprint"),
+			"Invalid Passbolt server key fingerprint",
+			"The server key fingerprint must be a hexadecimal GPG fingerprint of 40 or 64 characters, got "+strconv.Quote(fingerprint)+".",
 		)
+		return
+	}
+
+	httpConfig := tools.HTTPConfig{
+		CACertFile:    stringValueOrEnv(config.CACertFile, "PASSBOLT_CA_CERT_FILE"),
+		CACertPEM:     stringValueOrEnv(config.CACertPEM, "PASSBOLT_CA_CERT_PEM"),
+		ClientCert:    stringValueOrEnv(config.ClientCert, "PASSBOLT_CLIENT_CERT"),
+		ClientKey:     stringValueOrEnv(config.ClientKey, "PASSBOLT_CLIENT_KEY"),
+		TLSServerName: stringValueOrEnv(config.TLSServerName, "PASSBOLT_TLS_SERVER_NAME"),
+		ProxyURL:      stringValueOrEnv(config.ProxyURL, "PASSBOLT_PROXY_URL"),
+		NoProxy:       stringValueOrEnv(config.NoProxy, "PASSBOLT_NO_PROXY"),
 	}
 
+	if profile != "" {
+		httpConfig.LogFields = map[string]interface{}{"passbolt_profile": profile}
+	}
+
+	httpConfig.Headers, diags = loadHeaders(ctx, config.Headers)
+	resp.Diagnostics.Append(diags...)
+
+	httpConfig.Retry, diags = loadRetryConfig(config)
+	resp.Diagnostics.Append(diags...)
+
+	httpConfig.RateLimit, diags = loadRateLimitConfig(config)
+	resp.Diagnostics.Append(diags...)
+
+	httpConfig.InsecureSkipVerify, diags = boolValueOrEnv(config.InsecureSkipVerify, "insecure_skip_verify", "PASSBOLT_INSECURE_SKIP_VERIFY")
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	client, err := api.NewClient(nil, "", url, key, pass)
+	if httpConfig.InsecureSkipVerify {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"Passbolt server certificate verification disabled",
+			"The certificate of the Passbolt server is not verified. Secrets may be exposed to anyone able to intercept the connection.",
+		)
+	}
 
+	httpClient, err := tools.NewHTTPClient(httpConfig)
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Unable to connect to passbolt",
-			"Client Error: "+err.Error(),
+			"Invalid Passbolt HTTP configuration",
+			"The provider cannot create the HTTP client for Passbolt: "+err.Error(),
 		)
 		return
 	}
 
+	client, err := api.NewClient(httpClient, "terraform-provider-passbolt/"+p.version, url, key, pass)
+
+	if err != nil {
+		addLoginError(&resp.Diagnostics, tools.NewLoginError(err), keyInfo)
+		return
+	}
+
 	passboltClient := tools.PassboltClient{
 		Client:     client,
 		Url:        url,
-		Context:    context.TODO(),
 		Password:   pass,
 		PrivateKey: key,
+
+		ServerKeyFingerprint: fingerprint,
+		Profile:              profile,
+	}
+
+	if mfa != nil {
+		passboltClient.EnableMFA(*mfa)
 	}
 
 	// Make the client available during DataSource and Resource
 	// type Configure methods.
 
-	tools.Login(&passboltClient)
+	err = tools.Login(ctx, &passboltClient)
+	if err != nil {
+		var loginErr *tools.LoginError
+		if !errors.As(err, &loginErr) {
+			loginErr = tools.NewLoginError(err)
+		}
+		addLoginError(&resp.Diagnostics, loginErr, keyInfo)
+		return
+	}
 
 	resp.DataSourceData = &passboltClient
 	resp.ResourceData = &passboltClient
@@ -198,5 +526,477 @@ func (p *passboltProvider) Resources(_ context.Context) []func() resource.Resour
 		NewPasswordResource,
 		NewShareResource,
 		NewShareFolder,
+		NewResourcePermissions,
+		NewFolderPermissions,
+		NewGroupResource,
+		NewGroupMembershipResource,
+		NewUserResource,
+	}
+}
+
+// keyExpiryWarning is how long before its expiry a warning about the private
+// key is shown.
+const keyExpiryWarning = 30 * 24 * time.Hour
+
+// privateKeyDiagnostics reports the result of tools.InspectPrivateKey, warning
+// about keys expiring within keyExpiryWarning of now.
+func privateKeyDiagnostics(info tools.KeyInfo, err error, now time.Time) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	switch {
+	case errors.Is(err, tools.ErrKeyPassphrase):
+		diags.AddAttributeError(
+			path.Root("passphrase"),
+			"Invalid Passbolt passphrase",
+			"The private key could not be unlocked with the configured passphrase. "+
+				"Check the passphrase value in the configuration or the PASSBOLT_PASS environment variable.\n\n"+info.String(),
+		)
+		return diags
+	case errors.Is(err, tools.ErrKeyNotPrivate):
+		diags.AddAttributeError(
+			path.Root("private_key"),
+			"Invalid Passbolt private key",
+			"The configured key is a public key, the provider requires the private key of the Passbolt user.\n\n"+info.String(),
+		)
+		return diags
+	case err != nil:
+		diags.AddAttributeError(
+			path.Root("private_key"),
+			"Invalid Passbolt private key",
+			"The configured private key could not be parsed: "+err.Error(),
+		)
+		return diags
+	}
+
+	if info.Expires.IsZero() {
+		return diags
+	}
+
+	if !info.Expires.After(now) {
+		diags.AddAttributeError(
+			path.Root("private_key"),
+			"Expired Passbolt private key",
+			"The configured private key has expired, extend its expiry or configure a new key.\n\n"+info.String(),
+		)
+	} else if info.Expires.Sub(now) < keyExpiryWarning {
+		diags.AddAttributeWarning(
+			path.Root("private_key"),
+			"Passbolt private key expires soon",
+			fmt.Sprintf("The configured private key expires in %d days, extend its expiry or configure a new key.\n\n", int(info.Expires.Sub(now).Hours()/24))+info.String(),
+		)
+	}
+
+	return diags
+}
+
+// addLoginError turns a failed login into a diagnostic that explains the
+// likely cause to the practitioner. Errors caused by the private key describe
+// it with info.
+func addLoginError(diags *diag.Diagnostics, err *tools.LoginError, info tools.KeyInfo) {
+	switch err.Kind {
+	case tools.LoginErrorWrongPassphrase:
+		diags.AddAttributeError(
+			path.Root("passphrase"),
+			"Invalid Passbolt passphrase",
+			"The private key could not be unlocked with the configured passphrase. "+
+				"Check the passphrase value in the configuration or the PASSBOLT_PASS environment variable.\n\n"+
+				"Error: "+err.Err.Error()+"\n\n"+info.String(),
+		)
+	case tools.LoginErrorUnknownUserKey:
+		diags.AddAttributeError(
+			path.Root("private_key"),
+			"Unknown Passbolt user key",
+			"The Passbolt server did not accept the configured private key. "+
+				"Ensure the key belongs to an active Passbolt user of this instance.\n\n"+
+				"Error: "+err.Err.Error()+"\n\n"+info.String(),
+		)
+	case tools.LoginErrorServerUnreachable:
+		diags.AddAttributeError(
+			path.Root("base_url"),
+			"Unable to reach Passbolt",
+			"The Passbolt server could not be reached. Check the base_url value and the network connectivity to the server.\n\n"+
+				"Error: "+err.Err.Error(),
+		)
+	case tools.LoginErrorServerFingerprintMismatch:
+		diags.AddAttributeError(
+			path.Root("server_key_fingerprint"),
+			"Passbolt server key fingerprint mismatch",
+			"The GPG key of the Passbolt server does not match the configured server_key_fingerprint. "+
+				"The provider refused to log in as the server may be impersonated. If the server key was rotated, update the pinned fingerprint.\n\n"+
+				"Error: "+err.Err.Error(),
+		)
+	case tools.LoginErrorServerKeyMismatch:
+		diags.AddError(
+			"Passbolt server key mismatch",
+			"The public key returned by the Passbolt server for this user does not match the configured private key. "+
+				"This may indicate a misconfigured server or a man-in-the-middle attack.\n\n"+
+				"Error: "+err.Err.Error()+"\n\n"+info.String(),
+		)
+	default:
+		diags.AddError(
+			"Unable to log in to Passbolt",
+			"Login Error: "+err.Err.Error(),
+		)
+	}
+}
+
+// applyProfile reads the named profile and sets the attributes of config
+// which are not configured from it.
+func applyProfile(config *hashicupsProviderModel, name string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	file := stringValueOrEnv(config.ProfilesFile, "PASSBOLT_PROFILES_FILE")
+	if file == "" {
+		var err error
+		file, err = tools.DefaultProfilesFile()
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("profiles_file"),
+				"Unknown Passbolt profiles file",
+				"The default location of the profiles file could not be determined, set profiles_file or the PASSBOLT_PROFILES_FILE environment variable: "+err.Error(),
+			)
+			return diags
+		}
+	}
+
+	profile, err := tools.LoadProfile(file, name)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("profile"),
+			"Unable to load Passbolt profile",
+			err.Error(),
+		)
+		return diags
+	}
+
+	if config.URL.IsNull() && profile.ServerAddress != "" {
+		config.URL = types.StringValue(profile.ServerAddress)
+	}
+
+	if config.KEY.IsNull() && config.KeyFile.IsNull() && config.KeyBase64.IsNull() {
+		if profile.UserPrivateKey != "" {
+			config.KEY = types.StringValue(profile.UserPrivateKey)
+		} else if profile.UserPrivateKeyFile != "" {
+			config.KeyFile = types.StringValue(profile.UserPrivateKeyFile)
+		}
+	}
+
+	if config.PASS.IsNull() && profile.UserPassword != "" {
+		config.PASS = types.StringValue(profile.UserPassword)
+	}
+
+	if config.MFA == nil && profile.TOTPToken != "" {
+		config.MFA = &mfaModel{
+			TOTPSecret: types.StringValue(profile.TOTPToken),
+			TOTPCode:   types.StringNull(),
+		}
+	}
+
+	return diags
+}
+
+// stringValueOrEnv returns the configured value, or the value of the
+// environment variable env when the attribute is not set.
+func stringValueOrEnv(value types.String, env string) string {
+	if !value.IsNull() {
+		return value.ValueString()
+	}
+	return os.Getenv(env)
+}
+
+// boolValueOrEnv returns the configured value, or the parsed value of the
+// environment variable env when the attribute is not set.
+func boolValueOrEnv(value types.Bool, attribute, env string) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !value.IsNull() {
+		return value.ValueBool(), diags
+	}
+
+	raw := os.Getenv(env)
+	if raw == "" {
+		return false, diags
+	}
+
+	parsed, err := strconv.ParseBool(raw)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root(attribute),
+			"Invalid "+env+" environment variable",
+			"The value "+strconv.Quote(raw)+" of "+env+" is not a boolean.",
+		)
+	}
+	return parsed, diags
+}
+
+// loadRetryConfig returns the retry settings, falling back to the
+// PASSBOLT_MAX_RETRIES and PASSBOLT_RETRY_MAX_WAIT environment variables.
+func loadRetryConfig(config hashicupsProviderModel) (tools.RetryConfig, diag.Diagnostics) {
+	maxRetries, diags := int64ValueOrEnv(config.MaxRetries, "max_retries", "PASSBOLT_MAX_RETRIES", tools.DefaultMaxRetries)
+	if maxRetries < 0 {
+		diags.AddAttributeError(
+			path.Root("max_retries"),
+			"Invalid Passbolt max_retries",
+			"max_retries must not be negative.",
+		)
+	}
+
+	retry := tools.RetryConfig{
+		MaxRetries: int(maxRetries),
+		MaxWait:    tools.DefaultRetryMaxWait,
+	}
+
+	if raw := stringValueOrEnv(config.RetryMaxWait, "PASSBOLT_RETRY_MAX_WAIT"); raw != "" {
+		maxWait, err := time.ParseDuration(raw)
+		if err != nil || maxWait <= 0 {
+			diags.AddAttributeError(
+				path.Root("retry_max_wait"),
+				"Invalid Passbolt retry_max_wait",
+				"retry_max_wait must be a positive duration such as \"30s\", got "+strconv.Quote(raw)+".",
+			)
+		}
+		retry.MaxWait = maxWait
+	}
+
+	return retry, diags
+}
+
+// loadRateLimitConfig returns the client side rate limits, falling back to the
+// PASSBOLT_REQUESTS_PER_SECOND and PASSBOLT_MAX_CONCURRENT_REQUESTS
+// environment variables.
+func loadRateLimitConfig(config hashicupsProviderModel) (tools.RateLimitConfig, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	requestsPerSecond := config.RequestsPerSecond.ValueFloat64()
+	if config.RequestsPerSecond.IsNull() {
+		if raw := os.Getenv("PASSBOLT_REQUESTS_PER_SECOND"); raw != "" {
+			parsed, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				diags.AddAttributeError(
+					path.Root("requests_per_second"),
+					"Invalid PASSBOLT_REQUESTS_PER_SECOND environment variable",
+					"The value "+strconv.Quote(raw)+" of PASSBOLT_REQUESTS_PER_SECOND is not a number.",
+				)
+			}
+			requestsPerSecond = parsed
+		}
+	}
+	if requestsPerSecond < 0 {
+		diags.AddAttributeError(
+			path.Root("requests_per_second"),
+			"Invalid Passbolt requests_per_second",
+			"requests_per_second must not be negative.",
+		)
+	}
+
+	maxConcurrent, intDiags := int64ValueOrEnv(config.MaxConcurrentRequests, "max_concurrent_requests", "PASSBOLT_MAX_CONCURRENT_REQUESTS", 0)
+	diags.Append(intDiags...)
+	if maxConcurrent < 0 {
+		diags.AddAttributeError(
+			path.Root("max_concurrent_requests"),
+			"Invalid Passbolt max_concurrent_requests",
+			"max_concurrent_requests must not be negative.",
+		)
+	}
+
+	return tools.RateLimitConfig{
+		RequestsPerSecond:     requestsPerSecond,
+		MaxConcurrentRequests: int(maxConcurrent),
+	}, diags
+}
+
+// loadHeaders returns the configured headers, falling back to the
+// PASSBOLT_HEADERS environment variable.
+func loadHeaders(ctx context.Context, value types.Map) (map[string]string, diag.Diagnostics) {
+	headers := map[string]string{}
+
+	if !value.IsNull() {
+		diags := value.ElementsAs(ctx, &headers, false)
+		return headers, diags
+	}
+
+	var diags diag.Diagnostics
+	raw := os.Getenv("PASSBOLT_HEADERS")
+	if raw == "" {
+		return headers, diags
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		name, val, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			diags.AddAttributeError(
+				path.Root("headers"),
+				"Invalid PASSBOLT_HEADERS environment variable",
+				"PASSBOLT_HEADERS must be a comma separated list of name=value pairs.",
+			)
+			return nil, diags
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	}
+
+	return headers, diags
+}
+
+// int64ValueOrEnv returns the configured value, or the parsed value of the
+// environment variable env when the attribute is not set, or def when
+// neither is.
+func int64ValueOrEnv(value types.Int64, attribute, env string, def int64) (int64, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !value.IsNull() {
+		return value.ValueInt64(), diags
+	}
+
+	raw := os.Getenv(env)
+	if raw == "" {
+		return def, diags
+	}
+
+	parsed, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root(attribute),
+			"Invalid "+env+" environment variable",
+			"The value "+strconv.Quote(raw)+" of "+env+" is not an integer.",
+		)
+	}
+	return parsed, diags
+}
+
+// loadMFA returns the MFA settings from the mfa block, falling back to the
+// PASSBOLT_MFA_TOTP_SECRET and PASSBOLT_MFA_TOTP_CODE environment variables.
+// It returns nil when MFA is not configured.
+func loadMFA(config hashicupsProviderModel) (*tools.MFA, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	secret := os.Getenv("PASSBOLT_MFA_TOTP_SECRET")
+	code := os.Getenv("PASSBOLT_MFA_TOTP_CODE")
+
+	if config.MFA != nil && (!config.MFA.TOTPSecret.IsNull() || !config.MFA.TOTPCode.IsNull()) {
+		secret = config.MFA.TOTPSecret.ValueString()
+		code = config.MFA.TOTPCode.ValueString()
+	}
+
+	if secret == "" && code == "" {
+		if config.MFA != nil {
+			diags.AddAttributeError(
+				path.Root("mfa"),
+				"Missing Passbolt MFA configuration",
+				"The mfa block requires either totp_secret or totp_code to be set, in the configuration or via the "+
+					"PASSBOLT_MFA_TOTP_SECRET or PASSBOLT_MFA_TOTP_CODE environment variable.",
+			)
+		}
+		return nil, diags
+	}
+
+	if secret != "" && code != "" {
+		diags.AddAttributeError(
+			path.Root("mfa"),
+			"Conflicting Passbolt MFA configuration",
+			"Only one of totp_secret or totp_code may be set.",
+		)
+		return nil, diags
+	}
+
+	if secret != "" {
+		_, err := helper.GenerateOTPCode(secret, time.Now())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("mfa").AtName("totp_secret"),
+				"Invalid Passbolt TOTP secret",
+				"The TOTP secret must be a base32 encoded seed: "+err.Error(),
+			)
+			return nil, diags
+		}
+	}
+
+	return &tools.MFA{TOTPSecret: secret, TOTPCode: code}, diags
+}
+
+// privateKeySource is one of the mutually exclusive ways to provide the
+// private key, with the attribute and environment variable it maps to.
+type privateKeySource struct {
+	attribute string
+	env       string
+	value     types.String
+	decode    func(string) (string, error)
+}
+
+// loadPrivateKey returns the armored private key from whichever of
+// private_key, private_key_file or private_key_base64 is set. Values from the
+// configuration take precedence over environment variables; within each of the
+// two only a single source may be set.
+func loadPrivateKey(config hashicupsProviderModel) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	sources := []privateKeySource{
+		{"private_key", "PASSBOLT_KEY", config.KEY, func(v string) (string, error) { return v, nil }},
+		{"private_key_file", "PASSBOLT_KEY_FILE", config.KeyFile, readPrivateKeyFile},
+		{"private_key_base64", "PASSBOLT_KEY_BASE64", config.KeyBase64, decodePrivateKeyBase64},
+	}
+
+	var configured, fromEnv []privateKeySource
+	for _, source := range sources {
+		if !source.value.IsNull() {
+			configured = append(configured, source)
+		} else if os.Getenv(source.env) != "" {
+			source.value = types.StringValue(os.Getenv(source.env))
+			fromEnv = append(fromEnv, source)
+		}
+	}
+
+	selected := configured
+	if len(selected) == 0 {
+		selected = fromEnv
+	}
+
+	if len(selected) == 0 {
+		return "", diags
+	}
+
+	if len(selected) > 1 {
+		names := make([]string, 0, len(selected))
+		for _, source := range selected {
+			if len(configured) > 0 {
+				names = append(names, source.attribute)
+			} else {
+				names = append(names, source.env)
+			}
+		}
+		diags.AddAttributeError(
+			path.Root(selected[0].attribute),
+			"Conflicting Passbolt private key sources",
+			"Only one of private_key, private_key_file or private_key_base64 may be set, got: "+strings.Join(names, ", ")+".",
+		)
+		return "", diags
+	}
+
+	source := selected[0]
+	key, err := source.decode(source.value.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root(source.attribute),
+			"Invalid Passbolt private key",
+			"The private key provided via "+source.attribute+" could not be loaded: "+err.Error(),
+		)
+		return "", diags
+	}
+
+	return key, diags
+}
+
+func readPrivateKeyFile(name string) (string, error) {
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func decodePrivateKeyBase64(encoded string) (string, error) {
+	content, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return "", fmt.Errorf("decoding base64: %w", err)
 	}
+	return string(content), nil
 }
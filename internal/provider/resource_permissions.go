@@ -0,0 +1,202 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"terraform-provider-passbolt/tools"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &resourcePermissions{}
+	_ resource.ResourceWithConfigure   = &resourcePermissions{}
+	_ resource.ResourceWithImportState = &resourcePermissions{}
+)
+
+// NewResourcePermissions is a helper function to simplify the provider implementation.
+func NewResourcePermissions() resource.Resource {
+	return &resourcePermissions{}
+}
+
+// resourcePermissions manages all permissions of a password.
+type resourcePermissions struct {
+	client *tools.PassboltClient
+}
+
+type resourcePermissionsModel struct {
+	ID          types.String      `tfsdk:"id"`
+	ResourceId  types.String      `tfsdk:"resource_id"`
+	Permissions []permissionModel `tfsdk:"permissions"`
+	Timeouts    timeouts.Value    `tfsdk:"timeouts"`
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *resourcePermissions) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*tools.PassboltClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *passboltClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// Metadata returns the resource type name.
+func (r *resourcePermissions) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_resource_permissions"
+}
+
+// Schema defines the schema for the resource.
+func (r *resourcePermissions) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages all permissions of a password. Destroying it leaves the permissions in place.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"resource_id": schema.StringAttribute{
+				Description: "ID of the password.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"permissions": permissionsAttribute("password"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// Create replaces the permissions of the password by the planned ones.
+func (r *resourcePermissions) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan resourcePermissionsModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	setPermissions(ctx, r.client, "password", plan.ResourceId.ValueString(), plan.Permissions, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = plan.ResourceId
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *resourcePermissions) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state resourcePermissionsModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	permissions, err := getResourcePermissions(ctx, r.client, state.ResourceId.ValueString())
+	if tools.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read password permissions",
+			"Could not read the permissions of password "+state.ResourceId.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	state.ID = state.ResourceId
+	state.Permissions = permissionModels(permissions)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update replaces the permissions of the password by the planned ones.
+func (r *resourcePermissions) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan resourcePermissionsModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	setPermissions(ctx, r.client, "password", plan.ResourceId.ValueString(), plan.Permissions, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = plan.ResourceId
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete only removes the resource from the state, a password cannot be left
+// without permissions.
+func (r *resourcePermissions) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var resourceID types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("resource_id"), &resourceID)...)
+
+	tflog.Info(ctx, "Leaving the permissions of the password in place", map[string]interface{}{"resource_id": resourceID.ValueString()})
+}
+
+// ImportState imports the permissions of the password with the given ID.
+func (r *resourcePermissions) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("resource_id"), req.ID)...)
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"testing"
+)
+
+const (
+	testResourceID = "0a6a8d2f-4f9e-4bd6-8c3b-5d55e1b8a8a1"
+	testUserID     = "1c2d6b0e-64a8-4e24-a5f3-7a6c3a0c4d11"
+	testUser2ID    = "2b7e3a9c-1f6d-4c8e-9a0b-3d4e5f6a7b22"
+	testUser3ID    = "6a1d4e5f-8b92-4ca3-9d4e-3f4a5b6c7d66"
+	testGroupID    = "3f8a1b2c-5d6e-4f70-8a9b-0c1d2e3f4a33"
+)
+
+func TestResourcePermissionsUpdate(t *testing.T) {
+	current := []api.Permission{
+		{ID: "p1", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "User", AROForeignKey: testUserID, Type: 15},
+		{ID: "p2", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "Group", AROForeignKey: testGroupID, Type: 1},
+		{ID: "p3", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "User", AROForeignKey: testUser2ID, Type: 7},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []permissionModel
+		wantShared  []api.Permission
+		wantErr     bool
+	}{
+		{
+			name:        "unmanaged permissions removed",
+			permissions: []permissionModel{permission("User", testUserID, "owner")},
+			wantShared: []api.Permission{
+				{ID: "p2", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "Group", AROForeignKey: testGroupID, Type: 1, Delete: true},
+				{ID: "p3", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "User", AROForeignKey: testUser2ID, Type: 7, Delete: true},
+			},
+		},
+		{
+			name:        "permission changed",
+			permissions: []permissionModel{permission("User", testUserID, "owner"), permission("Group", testGroupID, "update"), permission("User", testUser2ID, "update")},
+			wantShared: []api.Permission{
+				{ID: "p2", ACO: "Resource", ACOForeignKey: testResourceID, ARO: "Group", AROForeignKey: testGroupID, Type: 7},
+			},
+		},
+		{
+			name:        "unchanged",
+			permissions: []permissionModel{permission("User", testUserID, "owner"), permission("Group", testGroupID, "read"), permission("User", testUser2ID, "update")},
+		},
+		{
+			name:        "last owner removed",
+			permissions: []permissionModel{permission("Group", testGroupID, "read")},
+			wantErr:     true,
+		},
+		{
+			name:        "last owner demoted",
+			permissions: []permissionModel{permission("User", testUserID, "update")},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			server.handlePassword(testResourceID, current)
+			r := &resourcePermissions{client: server.client()}
+
+			model := resourcePermissionsModel{
+				ID:         types.StringValue(testResourceID),
+				ResourceId: types.StringValue(testResourceID),
+				Timeouts:   nullTimeouts(),
+			}
+			state := model
+			state.Permissions = permissionModels(current)
+			model.Permissions = tt.permissions
+
+			req := resource.UpdateRequest{Plan: planOf(t, r, model), State: stateOf(t, r, state)}
+			resp := resource.UpdateResponse{State: emptyState(t, r)}
+			r.Update(context.Background(), req, &resp)
+
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Fatalf("Update() diagnostics = %v, wantErr %v", resp.Diagnostics, tt.wantErr)
+			}
+
+			shares := server.requested("PUT /share/resource/" + testResourceID + ".json")
+			if tt.wantShared == nil {
+				if len(shares) != 0 {
+					t.Fatalf("Update() shared %v, want no share", sharedPermissions(t, shares[0]))
+				}
+				return
+			}
+			if len(shares) != 1 {
+				t.Fatalf("Update() shared %d times, want once", len(shares))
+			}
+			assertPermissions(t, sharedPermissions(t, shares[0]), tt.wantShared)
+
+			var got resourcePermissionsModel
+			resp.Diagnostics.Append(resp.State.Get(context.Background(), &got)...)
+			if len(got.Permissions) != len(tt.permissions) {
+				t.Errorf("Update() state has %d permissions, want %d", len(got.Permissions), len(tt.permissions))
+			}
+		})
+	}
+}
+
+// assertPermissions compares permissions ignoring their order.
+func assertPermissions(t *testing.T, got, want []api.Permission) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got permissions %+v, want %+v", got, want)
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if g == w {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("got permissions %+v, missing %+v", got, w)
+		}
+	}
+}
@@ -3,18 +3,27 @@ package provider
 import (
 	"context"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/passbolt/go-passbolt/helper"
+	"strings"
 	"terraform-provider-passbolt/tools"
-	"github.com/hashicorp/go-uuid"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &shareFolder{}
-	_ resource.ResourceWithConfigure = &shareFolder{}
+	_ resource.Resource                     = &shareFolder{}
+	_ resource.ResourceWithConfigure        = &shareFolder{}
+	_ resource.ResourceWithConfigValidators = &shareFolder{}
+	_ resource.ResourceWithImportState      = &shareFolder{}
+	_ resource.ResourceWithUpgradeState     = &shareFolder{}
 )
 
 // NewshareFolder is a helper function to simplify the provider implementation.
@@ -28,12 +37,25 @@ type shareFolder struct {
 }
 
 type shareFolderModel struct {
-	ID           types.String `tfsdk:"id"`
-	FolderId types.String `tfsdk:"folder_id"`
-	ShareGroupId   types.String `tfsdk:"share_group_id"`
-	Modify			types.Bool   `tfsdk:"modify"`
-	
+	ID             types.String   `tfsdk:"id"`
+	FolderId       types.String   `tfsdk:"folder_id"`
+	ShareGroupId   types.String   `tfsdk:"share_group_id"`
+	ShareUserId    types.String   `tfsdk:"share_user_id"`
+	ShareGroupName types.String   `tfsdk:"share_group_name"`
+	ShareUserEmail types.String   `tfsdk:"share_user_email"`
+	Permission     types.String   `tfsdk:"permission"`
+	Modify         types.Bool     `tfsdk:"modify"`
+	Timeouts       timeouts.Value `tfsdk:"timeouts"`
+}
+
+// principal resolves the ARO and ARO ID the folder is shared with.
+func (m shareFolderModel) principal(ctx context.Context, client *tools.PassboltClient, diags *diag.Diagnostics) (string, string) {
+	return sharePrincipal(ctx, client, path.Empty(), m.ShareGroupId, m.ShareUserId, m.ShareGroupName, m.ShareUserEmail, diags)
+}
 
+// permissionType returns the Passbolt permission type to grant.
+func (m shareFolderModel) permissionType() int {
+	return sharePermissionType(m.Permission, m.Modify)
 }
 
 // Configure adds the provider configured client to the resource.
@@ -62,22 +84,72 @@ func (r *shareFolder) Metadata(_ context.Context, req resource.MetadataRequest,
 }
 
 // Schema defines the schema for the resource.
-func (r *shareFolder) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *shareFolder) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 2,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Computed: true,
+				Description: "Identifier of the share in the format <folder_id>:<Group|User>:<share_group_id|share_user_id>.",
+				Computed:    true,
 			},
 			"folder_id": schema.StringAttribute{
 				Required: true,
 			},
 			"share_group_id": schema.StringAttribute{
-				Required: true,
+				Description: "ID of the group to share the folder with. Exactly one of share_group_id, share_user_id, share_group_name and share_user_email must be set.",
+				Optional:    true,
+			},
+			"share_user_id": schema.StringAttribute{
+				Description: "ID of the user to share the folder with.",
+				Optional:    true,
+			},
+			"share_group_name": schema.StringAttribute{
+				Description: "Name of the group to share the folder with, looked up when applying.",
+				Optional:    true,
+			},
+			"share_user_email": schema.StringAttribute{
+				Description: "Email of the user to share the folder with, looked up when applying.",
+				Optional:    true,
+			},
+			"permission": schema.StringAttribute{
+				Description: "Permission granted on the folder, one of read, update or owner.",
+				Optional:    true,
+				Computed:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(permissionNames...),
+				},
 			},
 			"modify": schema.BoolAttribute{
-				Required: true,
+				Description:        "Whether the folder can be updated, use permission instead.",
+				DeprecationMessage: "Use permission = \"update\" or permission = \"read\" instead.",
+				Optional:           true,
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// ConfigValidators checks that the folder is shared with exactly one principal
+// and permission.
+func (r *shareFolder) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("share_group_id"),
+			path.MatchRoot("share_user_id"),
+			path.MatchRoot("share_group_name"),
+			path.MatchRoot("share_user_email"),
+		),
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("permission"),
+			path.MatchRoot("modify"),
+		),
 	}
 }
 
@@ -89,30 +161,43 @@ func (r *shareFolder) Create(ctx context.Context, req resource.CreateRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	typeperm := TypePerm(plan.Modify.ValueBool())
-	
-		if plan.ShareGroupId.ValueString() != "" {
-			var shares = []helper.ShareOperation{
-				{
-					Type:  typeperm,
-					ARO:   "Group",
-					AROID: plan.ShareGroupId.ValueString(),
-				},
-			}
 
-			shareErr := helper.ShareFolder(ctx, r.client.Client, plan.FolderId.ValueString(), shares)
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-			if shareErr != nil {
-				resp.Diagnostics.AddError("Cannot share resource", "")
-				return
-			}
-		}
-	genId, errId := uuid.GenerateUUID()
-	if errId != nil {
-		resp.Diagnostics.AddError("Cannot generate uuid", "")
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	typeperm := plan.permissionType()
+	aro, aroID := plan.principal(ctx, r.client, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var shares = []helper.ShareOperation{
+		{
+			Type:  typeperm,
+			ARO:   aro,
+			AROID: aroID,
+		},
+	}
+
+	shareErr := r.client.Do(ctx, func() error {
+		return helper.ShareFolder(ctx, r.client.Client, plan.FolderId.ValueString(), shares)
+	})
+
+	if shareErr != nil {
+		resp.Diagnostics.AddError(
+			"Error sharing folder",
+			"Could not share folder "+plan.FolderId.ValueString()+" with "+aro+" "+aroID+", unexpected error: "+shareErr.Error(),
+		)
 		return
 	}
-	plan.ID = types.StringValue(genId)
+	plan.ID = types.StringValue(shareID(plan.FolderId.ValueString(), aro, aroID))
+	plan.Permission = types.StringValue(permissionName(typeperm))
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
@@ -124,7 +209,63 @@ func (r *shareFolder) Create(ctx context.Context, req resource.CreateRequest, re
 
 // Read refreshes the Terraform state with the latest data.
 func (r *shareFolder) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state shareFolderModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	permissions, err := getFolderPermissions(ctx, r.client, state.FolderId.ValueString())
+	if tools.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read folder permissions",
+			"Could not read the permissions of folder "+state.FolderId.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// The share was removed outside of Terraform.
+	aro, aroID := shareIDPrincipal(state.ID)
+	permission := findPermission(permissions, aro, aroID)
+	if permission == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Permission = types.StringValue(permissionName(permission.Type))
+	// modify only describes read and update, so any other permission clears
+	// it to show the drift and set the configured permission back.
+	if !state.Modify.IsNull() {
+		switch permission.Type {
+		case TypePerm(true):
+			state.Modify = types.BoolValue(true)
+		case TypePerm(false):
+			state.Modify = types.BoolValue(false)
+		default:
+			state.Modify = types.BoolNull()
+		}
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 }
 
 // Update updates the resource and sets the updated Terraform state on success.
@@ -135,58 +276,87 @@ func (r *shareFolder) Update(ctx context.Context, req resource.UpdateRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
 	var plan shareFolderModel
 	diagp := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diagp...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	aro, aroID := plan.principal(ctx, r.client, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	typeperm := plan.permissionType()
+	oldARO, oldAROID := shareIDPrincipal(state.ID)
+	shareChanged := !state.FolderId.Equal(plan.FolderId) || oldARO != aro || oldAROID != aroID
 
 	//Deletes the sharing of the resource
-	if state.ShareGroupId.ValueString() != "" {
+	if shareChanged && oldAROID != "" {
 		var shares = []helper.ShareOperation{
 			{
 				Type:  -1,
-				ARO:   "Group",
-				AROID: state.ShareGroupId.ValueString(),
+				ARO:   oldARO,
+				AROID: oldAROID,
 			},
 		}
 
-		shareErr := helper.ShareFolder(ctx, r.client.Client, state.FolderId.ValueString(), shares)
+		shareErr := r.client.Do(ctx, func() error {
+			return helper.ShareFolder(ctx, r.client.Client, state.FolderId.ValueString(), shares)
+		})
 
 		if shareErr != nil {
-			resp.Diagnostics.AddError("Cannot delete share of resource", "")
+			resp.Diagnostics.AddError(
+				"Unable to revoke share",
+				"Could not revoke the share of folder "+state.FolderId.ValueString()+" with "+oldARO+" "+oldAROID+", unexpected error: "+shareErr.Error(),
+			)
 			return
 		}
 	}
 
-	//Creates the sharing of the resource
-	typeperm := TypePerm(plan.Modify.ValueBool())
-	if plan.ShareGroupId.ValueString() != "" {
+	//Creates the sharing of the resource, switching between permission and
+	//modify for the same permission type requires no change
+	if shareChanged || typeperm != state.permissionType() {
 		var shares = []helper.ShareOperation{
 			{
 				Type:  typeperm,
-				ARO:   "Group",
-				AROID: plan.ShareGroupId.ValueString(),
+				ARO:   aro,
+				AROID: aroID,
 			},
 		}
 
-		shareErr := helper.ShareFolder(ctx, r.client.Client, plan.FolderId.ValueString(), shares)
+		shareErr := r.client.Do(ctx, func() error {
+			return helper.ShareFolder(ctx, r.client.Client, plan.FolderId.ValueString(), shares)
+		})
 
 		if shareErr != nil {
-			resp.Diagnostics.AddError("Cannot share resource", "")
+			resp.Diagnostics.AddError(
+				"Unable to share folder",
+				"Could not share folder "+plan.FolderId.ValueString()+" with "+aro+" "+aroID+", unexpected error: "+shareErr.Error(),
+			)
 			return
 		}
 	}
-plan.ID = state.ID
+	plan.ID = types.StringValue(shareID(plan.FolderId.ValueString(), aro, aroID))
+	plan.Permission = types.StringValue(permissionName(typeperm))
 
-// Set state to fully populated data
-diagpl := resp.State.Set(ctx, plan)
-resp.Diagnostics.Append(diagpl...)
-if resp.Diagnostics.HasError() {
-	return
-}
+	// Set state to fully populated data
+	diagpl := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diagpl...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -198,21 +368,90 @@ func (r *shareFolder) Delete(ctx context.Context, req resource.DeleteRequest, re
 		return
 	}
 
-	
-		if state.ShareGroupId.ValueString() != "" {
-			var shares = []helper.ShareOperation{
-				{
-					Type:  -1,
-					ARO:   "Group",
-					AROID: state.ShareGroupId.ValueString(),
-				},
-			}
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-			shareErr := helper.ShareFolder(ctx, r.client.Client, state.FolderId.ValueString(), shares)
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
 
-			if shareErr != nil {
-				resp.Diagnostics.AddError("Cannot share resource", "")
-				return
-			}
+	if aro, aroID := shareIDPrincipal(state.ID); aroID != "" {
+		var shares = []helper.ShareOperation{
+			{
+				Type:  -1,
+				ARO:   aro,
+				AROID: aroID,
+			},
 		}
+
+		shareErr := r.client.Do(ctx, func() error {
+			return helper.ShareFolder(ctx, r.client.Client, state.FolderId.ValueString(), shares)
+		})
+
+		if shareErr != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting share",
+				"Could not revoke the share of folder "+state.FolderId.ValueString()+" with "+aro+" "+aroID+", unexpected error: "+shareErr.Error(),
+			)
+			return
+		}
+	}
+}
+
+// ImportState imports the share of a folder with a group or user, identified by
+// "folder_id/group_id" or its ID.
+func (r *shareFolder) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	folderID, aro, aroID, err := parseShareImportID(req.ID, "folder_id/group_id")
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	permissions, err := getFolderPermissions(ctx, r.client, folderID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read folder permissions",
+			"Could not read the permissions of folder "+folderID+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	permission := findPermission(permissions, aro, aroID)
+	if permission == nil {
+		resp.Diagnostics.AddError(
+			"Share not found",
+			fmt.Sprintf("The folder %s is not shared with the %s %s.", folderID, strings.ToLower(aro), aroID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), shareID(folderID, aro, aroID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("folder_id"), folderID)...)
+	if aro == "User" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("share_user_id"), aroID)...)
+	} else {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("share_group_id"), aroID)...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("permission"), permissionName(permission.Type))...)
+}
+
+// UpgradeState replaces the random IDs of version 0 by deterministic ones and
+// derives permission from modify.
+func (r *shareFolder) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: shareSchemaV0(ctx, "folder_id"),
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				upgradeShareStateV0(ctx, req, resp, "folder_id")
+			},
+		},
+		1: {
+			PriorSchema: shareSchemaV1(ctx, "folder_id"),
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				upgradeShareStateV1(ctx, req, resp, "folder_id")
+			},
+		},
+	}
 }
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// shareSchemaV0 is the schema of passbolt_share_resource and
+// passbolt_share_folder before their IDs became deterministic, acoAttribute
+// is the name of the attribute holding the password or folder ID.
+func shareSchemaV0(ctx context.Context, acoAttribute string) *schema.Schema {
+	return &schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			acoAttribute: schema.StringAttribute{
+				Required: true,
+			},
+			"share_group_id": schema.StringAttribute{
+				Required: true,
+			},
+			"modify": schema.BoolAttribute{
+				Required: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// shareSchemaV1 is the schema of the share resources before modify was
+// replaced by permission.
+func shareSchemaV1(ctx context.Context, acoAttribute string) *schema.Schema {
+	return &schema.Schema{
+		Version: 1,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			acoAttribute: schema.StringAttribute{
+				Required: true,
+			},
+			"share_group_id": schema.StringAttribute{
+				Optional: true,
+			},
+			"share_user_id": schema.StringAttribute{
+				Optional: true,
+			},
+			"modify": schema.BoolAttribute{
+				Required: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// upgradeShareStateV0 upgrades a version 0 share state, in which acoAttribute
+// holds the password or folder ID, and replaces its random ID by the
+// deterministic one derived from the state. Version 0 only shared with groups.
+func upgradeShareStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse, acoAttribute string) {
+	var acoID, groupID types.String
+	var modify types.Bool
+	var timeoutsValue timeouts.Value
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root(acoAttribute), &acoID)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("share_group_id"), &groupID)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("modify"), &modify)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("timeouts"), &timeoutsValue)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := types.StringValue(shareID(acoID.ValueString(), "Group", groupID.ValueString()))
+	setUpgradedShareState(ctx, resp, acoAttribute, id, acoID, groupID, types.StringNull(), modify, timeoutsValue)
+}
+
+// upgradeShareStateV1 upgrades a version 1 share state by deriving permission
+// from modify, which is kept until it is removed from the configuration.
+func upgradeShareStateV1(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse, acoAttribute string) {
+	var id, acoID, groupID, userID types.String
+	var modify types.Bool
+	var timeoutsValue timeouts.Value
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root(acoAttribute), &acoID)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("share_group_id"), &groupID)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("share_user_id"), &userID)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("modify"), &modify)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("timeouts"), &timeoutsValue)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	setUpgradedShareState(ctx, resp, acoAttribute, id, acoID, groupID, userID, modify, timeoutsValue)
+}
+
+// setUpgradedShareState writes the current share state.
+func setUpgradedShareState(ctx context.Context, resp *resource.UpgradeStateResponse, acoAttribute string,
+	id, acoID, groupID, userID types.String, modify types.Bool, timeoutsValue timeouts.Value) {
+	// The upgraded state starts out without a value, as for imports.
+	resp.State.Raw = tftypes.NewValue(resp.State.Schema.Type().TerraformType(ctx), nil)
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(acoAttribute), acoID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("share_group_id"), groupID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("share_user_id"), userID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("permission"), permissionName(TypePerm(modify.ValueBool())))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("modify"), modify)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("timeouts"), timeoutsValue)...)
+}
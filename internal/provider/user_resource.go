@@ -0,0 +1,400 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+	"strings"
+	"terraform-provider-passbolt/tools"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &userResource{}
+	_ resource.ResourceWithConfigure   = &userResource{}
+	_ resource.ResourceWithImportState = &userResource{}
+	_ resource.ResourceWithModifyPlan  = &userResource{}
+)
+
+// NewUserResource is a helper function to simplify the provider implementation.
+func NewUserResource() resource.Resource {
+	return &userResource{}
+}
+
+// userResource is the resource implementation.
+type userResource struct {
+	client *tools.PassboltClient
+}
+
+type userModel struct {
+	ID        types.String   `tfsdk:"id"`
+	Username  types.String   `tfsdk:"username"`
+	FirstName types.String   `tfsdk:"first_name"`
+	LastName  types.String   `tfsdk:"last_name"`
+	Role      types.String   `tfsdk:"role"`
+	Active    types.Bool     `tfsdk:"active"`
+	Timeouts  timeouts.Value `tfsdk:"timeouts"`
+}
+
+// userDeleteConflicts is the body Passbolt answers a user delete dry-run with
+// when the user is the sole owner of passwords or folders, or the sole
+// manager of groups. Older Passbolt versions omit the errors wrapper.
+type userDeleteConflicts struct {
+	Errors    *userDeleteConflicts `json:"errors"`
+	Resources struct {
+		SoleOwner []userDeleteConflict `json:"sole_owner"`
+	} `json:"resources"`
+	Folders struct {
+		SoleOwner []userDeleteConflict `json:"sole_owner"`
+	} `json:"folders"`
+	Groups struct {
+		SoleManager []userDeleteConflict `json:"sole_manager"`
+	} `json:"groups"`
+}
+
+type userDeleteConflict struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *userResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*tools.PassboltClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *passboltClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// Metadata returns the resource type name.
+func (r *userResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+}
+
+// Schema defines the schema for the resource.
+func (r *userResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a Passbolt user. Passbolt invites new users by email, they are active once they complete the setup.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"username": schema.StringAttribute{
+				Description: "Email address of the user, changing it replaces the user.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"first_name": schema.StringAttribute{
+				Required: true,
+			},
+			"last_name": schema.StringAttribute{
+				Required: true,
+			},
+			"role": schema.StringAttribute{
+				Description: "Role of the user, user or admin.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("user"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("user", "admin"),
+				},
+			},
+			"active": schema.BoolAttribute{
+				Description: "Whether the user completed the account setup.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// ModifyPlan warns when a user that is planned to be destroyed cannot be
+// deleted, so that ownership can be transferred first.
+func (r *userResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.Plan.Raw.IsNull() || req.State.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var userID types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &userID)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	conflicts, err := r.deleteDryRun(ctx, userID.ValueString())
+	if tools.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Unable to check user deletion",
+			"Could not check whether user "+userID.ValueString()+" can be deleted, unexpected error: "+err.Error(),
+		)
+		return
+	}
+	if len(conflicts) > 0 {
+		resp.Diagnostics.AddWarning(
+			"User cannot be deleted",
+			"Passbolt will refuse to delete user "+userID.ValueString()+" as long as they are the\n"+strings.Join(conflicts, "\n"),
+		)
+	}
+}
+
+// Create invites a new user.
+func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan userModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	var userID string
+	err := r.client.Do(ctx, func() (err error) {
+		userID, err = helper.CreateUser(ctx, r.client.Client, plan.Role.ValueString(), plan.Username.ValueString(), plan.FirstName.ValueString(), plan.LastName.ValueString())
+		return err
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating user",
+			"Could not create user "+plan.Username.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+	plan.ID = types.StringValue(userID)
+	// Invited users are active once they complete the setup.
+	plan.Active = types.BoolValue(false)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state userModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	var user *api.User
+	err := r.client.Do(ctx, func() (err error) {
+		user, err = r.client.Client.GetUser(ctx, state.ID.ValueString())
+		return err
+	})
+	if tools.IsNotFound(err) || (err == nil && user.Deleted) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read user",
+			"Could not read user "+state.ID.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	state.Username = types.StringValue(user.Username)
+	if user.Profile != nil {
+		state.FirstName = types.StringValue(user.Profile.FirstName)
+		state.LastName = types.StringValue(user.Profile.LastName)
+	}
+	if user.Role != nil {
+		state.Role = types.StringValue(user.Role.Name)
+	}
+	state.Active = types.BoolValue(user.Active)
+
+	// Set state
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan userModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state userModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	err := r.client.Do(ctx, func() error {
+		return helper.UpdateUser(ctx, r.client.Client, state.ID.ValueString(), plan.Role.ValueString(), plan.FirstName.ValueString(), plan.LastName.ValueString())
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to update user",
+			"Could not update user "+state.ID.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+	plan.ID = state.ID
+	plan.Active = state.Active
+
+	// Set state
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete deletes the user after checking with a dry-run that Passbolt allows
+// it, reporting what the user is the sole owner or manager of otherwise.
+func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state userModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	conflicts, err := r.deleteDryRun(ctx, state.ID.ValueString())
+	if tools.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting user",
+			"Could not check whether user "+state.ID.ValueString()+" can be deleted, unexpected error: "+err.Error(),
+		)
+		return
+	}
+	if len(conflicts) > 0 {
+		resp.Diagnostics.AddError(
+			"User cannot be deleted",
+			"Passbolt refuses to delete user "+state.ID.ValueString()+" as long as they are the\n"+strings.Join(conflicts, "\n")+
+				"\nTransfer the ownership or management to another user first.",
+		)
+		return
+	}
+
+	err = r.client.Do(ctx, func() error {
+		return helper.DeleteUser(ctx, r.client.Client, state.ID.ValueString())
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting user",
+			"Could not delete user, unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState imports an existing user by its Passbolt ID.
+func (r *userResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// deleteDryRun asks Passbolt whether the user can be deleted and returns a
+// description of every conflict preventing it.
+func (r *userResource) deleteDryRun(ctx context.Context, userID string) ([]string, error) {
+	var response *api.APIResponse
+	err := r.client.Do(ctx, func() (err error) {
+		response, err = r.client.Client.DoCustomRequest(ctx, "DELETE", "/users/"+userID+"/dry-run.json", "v2", nil, nil)
+		return err
+	})
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, api.ErrAPIResponseErrorStatusCode) || response == nil || len(response.Body) == 0 {
+		return nil, err
+	}
+
+	var conflicts userDeleteConflicts
+	if json.Unmarshal(response.Body, &conflicts) != nil {
+		return nil, err
+	}
+	if conflicts.Errors != nil {
+		conflicts = *conflicts.Errors
+	}
+
+	var descriptions []string
+	for _, conflict := range conflicts.Resources.SoleOwner {
+		descriptions = append(descriptions, fmt.Sprintf("- sole owner of password %s (%s)", conflict.Name, conflict.ID))
+	}
+	for _, conflict := range conflicts.Folders.SoleOwner {
+		descriptions = append(descriptions, fmt.Sprintf("- sole owner of folder %s (%s)", conflict.Name, conflict.ID))
+	}
+	for _, conflict := range conflicts.Groups.SoleManager {
+		descriptions = append(descriptions, fmt.Sprintf("- sole manager of group %s (%s)", conflict.Name, conflict.ID))
+	}
+	if len(descriptions) == 0 {
+		return nil, err
+	}
+	return descriptions, nil
+}
@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// userOf returns the state of the test user.
+func userOf() userModel {
+	return userModel{
+		ID:        types.StringValue(testUserID),
+		Username:  types.StringValue("ada@example.com"),
+		FirstName: types.StringValue("Ada"),
+		LastName:  types.StringValue("Lovelace"),
+		Role:      types.StringValue("user"),
+		Active:    types.BoolValue(true),
+		Timeouts:  nullTimeouts(),
+	}
+}
+
+// testDeleteConflicts is what Passbolt lists when the test user is the sole
+// owner or manager of something.
+var testDeleteConflicts = map[string]interface{}{
+	"resources": map[string]interface{}{
+		"sole_owner": []map[string]string{{"id": testResourceID, "name": "Database"}},
+	},
+	"folders": map[string]interface{}{
+		"sole_owner": []map[string]string{{"id": testFolderID, "name": "Infrastructure"}},
+	},
+	"groups": map[string]interface{}{
+		"sole_manager": []map[string]string{{"id": testGroupID, "name": "Ops"}},
+	},
+}
+
+func TestUserDelete(t *testing.T) {
+	dryRun := "DELETE /users/" + testUserID + "/dry-run.json"
+	deleteUser := "DELETE /users/" + testUserID + ".json"
+
+	tests := []struct {
+		name       string
+		dryRun     http.HandlerFunc
+		wantErr    string
+		wantDelete bool
+	}{
+		{
+			name: "allowed",
+			dryRun: func(w http.ResponseWriter, _ *http.Request) {
+				writeAPIResponse(w, nil)
+			},
+			wantDelete: true,
+		},
+		{
+			name: "conflicts",
+			dryRun: func(w http.ResponseWriter, _ *http.Request) {
+				writeAPIErrorBody(w, http.StatusBadRequest, "The user cannot be deleted.", map[string]interface{}{"errors": testDeleteConflicts})
+			},
+			wantErr: "User cannot be deleted",
+		},
+		{
+			name: "conflicts without errors wrapper",
+			dryRun: func(w http.ResponseWriter, _ *http.Request) {
+				writeAPIErrorBody(w, http.StatusBadRequest, "The user cannot be deleted.", testDeleteConflicts)
+			},
+			wantErr: "User cannot be deleted",
+		},
+		{
+			name: "other error",
+			dryRun: func(w http.ResponseWriter, _ *http.Request) {
+				writeAPIError(w, http.StatusInternalServerError, "Internal error.")
+			},
+			wantErr: "Error deleting user",
+		},
+		{
+			name: "already deleted",
+			dryRun: func(w http.ResponseWriter, _ *http.Request) {
+				writeAPIError(w, http.StatusNotFound, "The user does not exist.")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			server.handleFunc(dryRun, tt.dryRun)
+			server.handle(deleteUser, nil)
+			r := &userResource{client: server.client()}
+
+			resp := resource.DeleteResponse{State: stateOf(t, r, userOf())}
+			r.Delete(context.Background(), resource.DeleteRequest{State: resp.State}, &resp)
+
+			if deletes := len(server.requested(deleteUser)); (deletes == 1) != tt.wantDelete {
+				t.Errorf("Delete() deleted the user %d times, want delete %v", deletes, tt.wantDelete)
+			}
+			if tt.wantErr == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("Delete() diagnostics = %v", resp.Diagnostics)
+				}
+				return
+			}
+			if !resp.Diagnostics.HasError() {
+				t.Fatalf("Delete() succeeded, want error %q", tt.wantErr)
+			}
+			diag := resp.Diagnostics.Errors()[0]
+			if diag.Summary() != tt.wantErr {
+				t.Fatalf("Delete() error = %q, want %q", diag.Summary(), tt.wantErr)
+			}
+			if tt.wantErr != "User cannot be deleted" {
+				return
+			}
+			for _, want := range []string{
+				"- sole owner of password Database (" + testResourceID + ")",
+				"- sole owner of folder Infrastructure (" + testFolderID + ")",
+				"- sole manager of group Ops (" + testGroupID + ")",
+			} {
+				if !strings.Contains(diag.Detail(), want) {
+					t.Errorf("Delete() error detail = %q, want it to list %q", diag.Detail(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestUserRead(t *testing.T) {
+	tests := []struct {
+		name        string
+		user        *api.User
+		wantRemoved bool
+	}{
+		{
+			name: "active",
+			user: &api.User{
+				ID:       testUserID,
+				Username: "ada@example.com",
+				Active:   true,
+				Profile:  &api.Profile{FirstName: "Ada", LastName: "King"},
+				Role:     &api.Role{Name: "admin"},
+			},
+		},
+		{name: "deleted", user: &api.User{ID: testUserID, Deleted: true}, wantRemoved: true},
+		{name: "not found", wantRemoved: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePassbolt(t)
+			if tt.user != nil {
+				server.handle("GET /users/"+testUserID+".json", tt.user)
+			} else {
+				server.handleNotFound("GET /users/" + testUserID + ".json")
+			}
+			r := &userResource{client: server.client()}
+
+			state := stateOf(t, r, userOf())
+			resp := resource.ReadResponse{State: state}
+			r.Read(context.Background(), resource.ReadRequest{State: state}, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Read() diagnostics = %v", resp.Diagnostics)
+			}
+			if resp.State.Raw.IsNull() != tt.wantRemoved {
+				t.Fatalf("Read() removed the user %v, want %v", resp.State.Raw.IsNull(), tt.wantRemoved)
+			}
+			if tt.wantRemoved {
+				return
+			}
+
+			var got userModel
+			resp.Diagnostics.Append(resp.State.Get(context.Background(), &got)...)
+			if got.LastName.ValueString() != "King" || got.Role.ValueString() != "admin" {
+				t.Errorf("Read() last name %s and role %s, want King and admin", got.LastName, got.Role)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// stringValueOrNull maps the empty strings Passbolt returns for unset
+// optional fields to null, so they match an omitted attribute.
+func stringValueOrNull(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 
 	"terraform-provider-passbolt/internal/provider"
+	"terraform-provider-passbolt/tools"
 )
 
 // Run "go generate" to format example terraform files and generate the docs for the registry/website
@@ -50,6 +51,10 @@ func main() {
 
 	err := providerserver.Serve(context.Background(), provider.New(version), opts)
 
+	// Serve returns once Terraform stops the provider, close the sessions
+	// opened while it was running.
+	tools.LogoutAll(context.Background())
+
 	if err != nil {
 		log.Fatal(err.Error())
 	}
@@ -0,0 +1,283 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ProtonMail/gopenpgp/v2/helper"
+	"github.com/passbolt/go-passbolt/api"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+const (
+	testUserID    = "1c2d6b0e-64a8-4e24-a5f3-7a6c3a0c4d11"
+	testAuthToken = "gpgauthv1.3.0|36|0a6a8d2f-4f9e-4bd6-8c3b-5d55e1b8a8a1|gpgauthv1.3.0"
+)
+
+// fakePassbolt implements the GPGAuth login of Passbolt for the key of a
+// test user, and serves the routes registered with handle to logged in
+// clients only.
+type fakePassbolt struct {
+	t          *testing.T
+	server     *httptest.Server
+	privateKey string
+	publicKey  string
+
+	mu       sync.Mutex
+	handlers map[string]http.HandlerFunc
+	sessions map[string]bool
+	logins   int
+	// unknownUser makes logins fail as for a key unknown to the server.
+	unknownUser bool
+	// acceptMFA decides whether the TOTP code of an MFA verification is
+	// accepted, MFA is only required when it is set.
+	acceptMFA   func(code string) bool
+	mfaSessions map[string]bool
+	requests    map[string]int
+}
+
+func newFakePassbolt(t *testing.T) *fakePassbolt {
+	key, privateKey := generateKey(t, 0)
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakePassbolt{
+		t:           t,
+		privateKey:  privateKey,
+		publicKey:   publicKey,
+		handlers:    map[string]http.HandlerFunc{},
+		sessions:    map[string]bool{},
+		mfaSessions: map[string]bool{},
+		requests:    map[string]int{},
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakePassbolt) serve(w http.ResponseWriter, r *http.Request) {
+	route := r.Method + " " + r.URL.Path
+
+	f.mu.Lock()
+	f.requests[route]++
+	handler, ok := f.handlers[route]
+	f.mu.Unlock()
+
+	switch route {
+	case "POST /auth/login.json":
+		f.login(w, r)
+		return
+	case "GET /auth/logout.json":
+		f.mu.Lock()
+		delete(f.sessions, sessionCookie(r))
+		f.mu.Unlock()
+		writeAPIResponse(w, nil)
+		return
+	}
+
+	if !f.loggedIn(r) {
+		writeAPIError(w, http.StatusUnauthorized, "Authentication is required to continue.")
+		return
+	}
+
+	if route == "POST /mfa/verify/totp.json" {
+		f.verifyMFA(w, r)
+		return
+	}
+	if !f.mfaVerified(r) {
+		writeMFAChallenge(w)
+		return
+	}
+
+	switch {
+	case route == "GET /users/me.json":
+		http.SetCookie(w, &http.Cookie{Name: "csrfToken", Value: "csrf"})
+		writeAPIResponse(w, api.User{ID: testUserID, GPGKey: &api.GPGKey{ArmoredKey: f.publicKey}})
+	case ok:
+		handler(w, r)
+	default:
+		f.t.Errorf("unexpected request %s", route)
+		writeAPIError(w, http.StatusNotFound, "The route does not exist.")
+	}
+}
+
+// login answers both stages of the GPGAuth login, the first one with a token
+// encrypted to the user key and the second one with a new session once the
+// token was decrypted.
+func (f *fakePassbolt) login(w http.ResponseWriter, r *http.Request) {
+	var login api.Login
+	if err := json.NewDecoder(r.Body).Decode(&login); err != nil || login.Auth == nil {
+		writeAPIError(w, http.StatusBadRequest, "The request is invalid.")
+		return
+	}
+
+	f.mu.Lock()
+	unknownUser := f.unknownUser
+	f.mu.Unlock()
+	if unknownUser {
+		writeAPIError(w, http.StatusNotFound, "The user does not exist.")
+		return
+	}
+
+	if login.Auth.Token == "" {
+		token, err := helper.EncryptMessageArmored(f.publicKey, testAuthToken)
+		if err != nil {
+			f.t.Error(err)
+		}
+		w.Header().Set("X-GPGAuth-User-Auth-Token", url.QueryEscape(token))
+		writeAPIResponse(w, nil)
+		return
+	}
+	if login.Auth.Token != testAuthToken {
+		writeAPIError(w, http.StatusForbidden, "The authentication failed.")
+		return
+	}
+
+	f.mu.Lock()
+	f.logins++
+	session := fmt.Sprintf("session-%d", f.logins)
+	f.sessions[session] = true
+	f.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{Name: "passbolt_session", Value: session})
+	writeAPIResponse(w, nil)
+}
+
+// verifyMFA answers an MFA verification with a passbolt_mfa cookie when
+// acceptMFA accepts its code.
+func (f *fakePassbolt) verifyMFA(w http.ResponseWriter, r *http.Request) {
+	var answer api.MFAChallengeResponse
+	if err := json.NewDecoder(r.Body).Decode(&answer); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "The request is invalid.")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.acceptMFA == nil || !f.acceptMFA(answer.TOTP) {
+		writeAPIError(w, http.StatusBadRequest, "The OTP is not valid.")
+		return
+	}
+	token := fmt.Sprintf("mfa-%d", len(f.mfaSessions)+1)
+	f.mfaSessions[token] = true
+	http.SetCookie(w, &http.Cookie{Name: "passbolt_mfa", Value: token})
+	writeAPIResponse(w, nil)
+}
+
+func (f *fakePassbolt) mfaVerified(r *http.Request) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.acceptMFA == nil {
+		return true
+	}
+	cookie, err := r.Cookie("passbolt_mfa")
+	return err == nil && f.mfaSessions[cookie.Value]
+}
+
+// writeMFAChallenge answers as Passbolt does for requests which need a TOTP
+// code to be verified first.
+func writeMFAChallenge(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"header": api.APIHeader{Status: "error", Code: http.StatusForbidden, URL: "/mfa/verify/error.json", Message: "MFA authentication is required."},
+		"body":   api.MFAChallenge{Provider: api.MFAProviders{TOTP: "/mfa/verify/totp.json"}},
+	})
+}
+
+// requireMFA makes the server require a TOTP code accepted by accept, once
+// per MFA cookie.
+func (f *fakePassbolt) requireMFA(accept func(code string) bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.acceptMFA = accept
+}
+
+// expireMFA ends every verified MFA, so the next request requires a new code.
+func (f *fakePassbolt) expireMFA() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mfaSessions = map[string]bool{}
+}
+
+func (f *fakePassbolt) loggedIn(r *http.Request) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sessions[sessionCookie(r)]
+}
+
+func sessionCookie(r *http.Request) string {
+	cookie, err := r.Cookie("passbolt_session")
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// handle serves requests to route, "<method> <path>", to logged in clients.
+func (f *fakePassbolt) handle(route string, handler http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[route] = handler
+}
+
+// expireSessions ends every session, so the next request of each client is
+// answered as Passbolt answers expired sessions.
+func (f *fakePassbolt) expireSessions() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions = map[string]bool{}
+}
+
+// rejectLogins makes the following logins fail.
+func (f *fakePassbolt) rejectLogins() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unknownUser = true
+}
+
+// loginCount returns the number of completed logins.
+func (f *fakePassbolt) loginCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.logins
+}
+
+// requested returns the number of requests received for route.
+func (f *fakePassbolt) requested(route string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[route]
+}
+
+// client returns a client of the test user for the fake server, which is not
+// logged in yet.
+func (f *fakePassbolt) client() *PassboltClient {
+	client, err := api.NewClient(f.server.Client(), "", f.server.URL, f.privateKey, testPassphrase)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return &PassboltClient{Client: client, Url: f.server.URL, PrivateKey: f.privateKey, Password: testPassphrase}
+}
+
+func writeAPIResponse(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"header": api.APIHeader{Status: "success", Code: http.StatusOK},
+		"body":   body,
+	})
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"header": api.APIHeader{Status: "error", Code: status, Message: message},
+		"body":   nil,
+	})
+}
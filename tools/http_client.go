@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"golang.org/x/net/http/httpproxy"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// HTTPConfig holds the transport settings of the HTTP client passed to
+// go-passbolt.
+type HTTPConfig struct {
+	// CACertFile and CACertPEM add certificate authorities to the system pool.
+	CACertFile string
+	CACertPEM  string
+	// ClientCert and ClientKey are either PEM encoded or paths to PEM files.
+	ClientCert         string
+	ClientKey          string
+	TLSServerName      string
+	InsecureSkipVerify bool
+
+	// ProxyURL overrides the proxy from the HTTP_PROXY and HTTPS_PROXY
+	// environment variables. Credentials may be passed as URL user info.
+	ProxyURL string
+	// NoProxy lists hosts that bypass the proxy, in the NO_PROXY format. It
+	// overrides the NO_PROXY environment variable.
+	NoProxy string
+	// Headers are added to every request.
+	Headers map[string]string
+
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+
+	// LogFields are added to the log entries written by the transport.
+	LogFields map[string]interface{}
+}
+
+// headerTransport adds static headers to every request.
+type headerTransport struct {
+	headers map[string]string
+	next    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// NewHTTPClient builds the HTTP client used to talk to Passbolt.
+func NewHTTPClient(config HTTPConfig) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := newProxyFunc(config)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if len(config.Headers) > 0 {
+		roundTripper = &headerTransport{headers: config.Headers, next: roundTripper}
+	}
+	if config.RateLimit.RequestsPerSecond > 0 || config.RateLimit.MaxConcurrentRequests > 0 {
+		roundTripper = newRateLimitTransport(config.RateLimit, roundTripper)
+	}
+	if config.Retry.MaxRetries > 0 {
+		roundTripper = &retryTransport{config: config.Retry, logFields: config.LogFields, next: roundTripper}
+	}
+
+	return &http.Client{Transport: roundTripper}, nil
+}
+
+// newProxyFunc returns the proxy configured by the environment, with the
+// proxy URL and the hosts bypassing it overridden by config when set.
+func newProxyFunc(config HTTPConfig) (func(*http.Request) (*url.URL, error), error) {
+	proxyConfig := httpproxy.FromEnvironment()
+
+	if config.ProxyURL != "" {
+		if _, err := url.Parse(config.ProxyURL); err != nil {
+			return nil, fmt.Errorf("parsing proxy URL: %w", err)
+		}
+		proxyConfig.HTTPProxy = config.ProxyURL
+		proxyConfig.HTTPSProxy = config.ProxyURL
+	}
+	if config.NoProxy != "" {
+		proxyConfig.NoProxy = config.NoProxy
+	}
+
+	proxyFunc := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
+
+func newTLSConfig(config HTTPConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         config.TLSServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CACertFile != "" || config.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if config.CACertFile != "" {
+			pem, err := os.ReadFile(config.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA certificate file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no PEM certificates found in %s", config.CACertFile)
+			}
+		}
+
+		if config.CACertPEM != "" && !pool.AppendCertsFromPEM([]byte(config.CACertPEM)) {
+			return nil, errors.New("no PEM certificates found in the CA certificate")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.ClientCert != "" || config.ClientKey != "" {
+		if config.ClientCert == "" || config.ClientKey == "" {
+			return nil, errors.New("client certificate and client key must be set together")
+		}
+
+		cert, err := readPEM(config.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("reading client certificate: %w", err)
+		}
+		key, err := readPEM(config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("reading client key: %w", err)
+		}
+
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	return tlsConfig, nil
+}
+
+// readPEM returns value itself when it is PEM encoded and otherwise treats it
+// as the path of a PEM file.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN ") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+	"net/http"
+	"time"
+)
+
+// mfaAttempts is the number of TOTP codes tried before a challenge is
+// considered failed. Codes generated from a seed are retried in the next
+// time window to tolerate small clock differences.
+const mfaAttempts = 3
+
+// mfaWait waits for the next TOTP time window, it is replaced in tests.
+var mfaWait = sleep
+
+// MFA configures how the client answers Passbolt MFA challenges. Exactly one
+// of TOTPSecret and TOTPCode is expected to be set.
+type MFA struct {
+	// TOTPSecret is the base32 encoded seed used to generate codes locally.
+	TOTPSecret string
+	// TOTPCode is a static code, only usable for a single challenge. It does
+	// not survive a re-login if the server asks for MFA again by then.
+	TOTPCode string
+}
+
+// EnableMFA installs an MFA callback on the go-passbolt client. go-passbolt
+// keeps the cookie obtained from a successful challenge, also across
+// re-logins, and sends it with every following request, so a new challenge is
+// only answered once the server stops accepting it.
+func (c *PassboltClient) EnableMFA(mfa MFA) {
+	c.MFA = &mfa
+	c.Client.MFACallback = c.answerMFAChallenge
+}
+
+func (c *PassboltClient) answerMFAChallenge(ctx context.Context, client *api.Client, res *api.APIResponse) (http.Cookie, error) {
+	c.mfaMutex.Lock()
+	defer c.mfaMutex.Unlock()
+
+	ctx = c.LogContext(ctx)
+
+	var challenge api.MFAChallenge
+	err := json.Unmarshal(res.Body, &challenge)
+	if err != nil {
+		return http.Cookie{}, fmt.Errorf("parsing MFA challenge: %w", err)
+	}
+	if challenge.Provider.TOTP == "" {
+		return http.Cookie{}, errors.New("the Passbolt server did not offer TOTP as MFA provider")
+	}
+
+	if c.MFA.TOTPSecret == "" && c.mfaCodeUsed {
+		return http.Cookie{}, errors.New("the static MFA code has already been used, configure a TOTP secret to answer further challenges")
+	}
+
+	tflog.Debug(ctx, "Answering Passbolt MFA challenge")
+
+	for attempt := 0; attempt < mfaAttempts; attempt++ {
+		code := c.MFA.TOTPCode
+		if c.MFA.TOTPSecret != "" {
+			code, err = helper.GenerateOTPCode(c.MFA.TOTPSecret, time.Now())
+			if err != nil {
+				return http.Cookie{}, fmt.Errorf("generating TOTP code: %w", err)
+			}
+		} else {
+			c.mfaCodeUsed = true
+		}
+
+		var raw *http.Response
+		raw, _, err = client.DoCustomRequestAndReturnRawResponse(ctx, "POST", "mfa/verify/totp.json", "v2", api.MFAChallengeResponse{TOTP: code}, nil)
+		if err == nil {
+			for _, cookie := range raw.Cookies() {
+				if cookie.Name == "passbolt_mfa" {
+					tflog.Info(ctx, "Passbolt MFA challenge answered")
+					return *cookie, nil
+				}
+			}
+			return http.Cookie{}, errors.New("the Passbolt server did not return an MFA cookie")
+		}
+		if !errors.Is(err, api.ErrAPIResponseErrorStatusCode) || c.MFA.TOTPSecret == "" {
+			return http.Cookie{}, fmt.Errorf("verifying TOTP code: %w", err)
+		}
+
+		if attempt == mfaAttempts-1 {
+			break
+		}
+
+		// The code was rejected, wait for the next time window.
+		tflog.Warn(ctx, "Passbolt rejected the TOTP code, retrying", map[string]interface{}{"attempt": attempt + 1})
+		wait := time.Until(time.Now().Truncate(30 * time.Second).Add(30 * time.Second))
+		if err := mfaWait(ctx, wait); err != nil {
+			return http.Cookie{}, err
+		}
+	}
+
+	return http.Cookie{}, fmt.Errorf("TOTP code rejected %d times: %w", mfaAttempts, err)
+}
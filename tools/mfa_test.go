@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+var totpCodePattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// skipMFAWait replaces the wait between TOTP attempts for the duration of
+// the test and returns the number of waits.
+func skipMFAWait(t *testing.T) *int {
+	waits := 0
+	mfaWait = func(context.Context, time.Duration) error {
+		waits++
+		return nil
+	}
+	t.Cleanup(func() { mfaWait = sleep })
+	return &waits
+}
+
+func TestMFALogin(t *testing.T) {
+	tests := []struct {
+		name string
+		mfa  MFA
+		// rejected is the number of codes rejected before a valid one.
+		rejected     int
+		wantCodes    int
+		wantWaits    int
+		wantLoginErr bool
+	}{
+		{name: "static code", mfa: MFA{TOTPCode: "123456"}, wantCodes: 1},
+		{name: "wrong static code", mfa: MFA{TOTPCode: "123456"}, rejected: 1, wantCodes: 1, wantLoginErr: true},
+		{name: "seed", mfa: MFA{TOTPSecret: testTOTPSecret}, wantCodes: 1},
+		{name: "seed retried", mfa: MFA{TOTPSecret: testTOTPSecret}, rejected: 2, wantCodes: 3, wantWaits: 2},
+		{name: "seed rejected", mfa: MFA{TOTPSecret: testTOTPSecret}, rejected: mfaAttempts, wantCodes: mfaAttempts, wantWaits: mfaAttempts - 1, wantLoginErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waits := skipMFAWait(t)
+			server := newFakePassbolt(t)
+
+			var codes []string
+			server.requireMFA(func(code string) bool {
+				codes = append(codes, code)
+				return len(codes) > tt.rejected
+			})
+
+			client := server.client()
+			client.EnableMFA(tt.mfa)
+
+			err := Login(context.Background(), client)
+			var loginErr *LoginError
+			if tt.wantLoginErr && !errors.As(err, &loginErr) {
+				t.Fatalf("Login() error = %v, want a LoginError", err)
+			}
+			if !tt.wantLoginErr && err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+			if len(codes) != tt.wantCodes {
+				t.Errorf("Login() sent %d codes, want %d", len(codes), tt.wantCodes)
+			}
+			if *waits != tt.wantWaits {
+				t.Errorf("Login() waited %d times, want %d", *waits, tt.wantWaits)
+			}
+			for _, code := range codes {
+				if tt.mfa.TOTPCode != "" && code != tt.mfa.TOTPCode {
+					t.Errorf("Login() sent code %q, want the static code %q", code, tt.mfa.TOTPCode)
+				}
+				if !totpCodePattern.MatchString(code) {
+					t.Errorf("Login() sent code %q, want six digits", code)
+				}
+			}
+		})
+	}
+}
+
+func TestMFAStaticCodeUsedOnce(t *testing.T) {
+	skipMFAWait(t)
+	ctx := context.Background()
+	server := newFakePassbolt(t)
+
+	codes := 0
+	server.requireMFA(func(string) bool {
+		codes++
+		return true
+	})
+
+	client := server.client()
+	client.EnableMFA(MFA{TOTPCode: "123456"})
+	if err := Login(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server asks for MFA again after the session expired.
+	server.expireSessions()
+	server.expireMFA()
+
+	err := client.Do(ctx, getResources(ctx, client))
+	if err == nil {
+		t.Fatal("Do() answered a second MFA challenge with the static code")
+	}
+	if codes != 1 {
+		t.Errorf("the static code was sent %d times, want once", codes)
+	}
+}
+
+func TestMFASeedAnswersEveryChallenge(t *testing.T) {
+	skipMFAWait(t)
+	ctx := context.Background()
+	server := newFakePassbolt(t)
+
+	codes := 0
+	server.requireMFA(func(string) bool {
+		codes++
+		return true
+	})
+
+	server.handle("GET /resources.json", func(w http.ResponseWriter, _ *http.Request) {
+		writeAPIResponse(w, []interface{}{})
+	})
+
+	client := server.client()
+	client.EnableMFA(MFA{TOTPSecret: testTOTPSecret})
+	if err := Login(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	server.expireSessions()
+	server.expireMFA()
+
+	if err := client.Do(ctx, getResources(ctx, client)); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if codes != 2 {
+		t.Errorf("sent %d codes, want 2", codes)
+	}
+}
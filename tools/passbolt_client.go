@@ -2,8 +2,14 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/passbolt/go-passbolt/api"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
 )
 
 type PassboltClient struct {
@@ -11,13 +17,119 @@ type PassboltClient struct {
 	Url        string
 	PrivateKey string
 	Password   string
-	Context    context.Context
+	MFA        *MFA
+	// ServerKeyFingerprint pins the GPG key of the Passbolt server, it is
+	// verified before logging in when set.
+	ServerKeyFingerprint string
+	// Profile is the name of the credential profile the client was
+	// configured from, if any.
+	Profile string
+
+	mfaMutex    sync.Mutex
+	mfaCodeUsed bool
+
+	sessionMutex      sync.RWMutex
+	sessionGeneration uint64
+}
+
+// LogContext adds the profile of the client to the log fields of ctx.
+func (c *PassboltClient) LogContext(ctx context.Context) context.Context {
+	if c.Profile == "" {
+		return ctx
+	}
+	return tflog.SetField(ctx, "passbolt_profile", c.Profile)
+}
+
+// LoginErrorKind describes why a login attempt failed.
+type LoginErrorKind int
+
+const (
+	LoginErrorUnknown LoginErrorKind = iota
+	LoginErrorWrongPassphrase
+	LoginErrorUnknownUserKey
+	LoginErrorServerUnreachable
+	LoginErrorServerKeyMismatch
+	LoginErrorServerFingerprintMismatch
+)
+
+func (k LoginErrorKind) String() string {
+	switch k {
+	case LoginErrorWrongPassphrase:
+		return "wrong passphrase"
+	case LoginErrorUnknownUserKey:
+		return "unknown user key"
+	case LoginErrorServerUnreachable:
+		return "server unreachable"
+	case LoginErrorServerKeyMismatch:
+		return "server key mismatch"
+	case LoginErrorServerFingerprintMismatch:
+		return "server key fingerprint mismatch"
+	default:
+		return "login failed"
+	}
 }
 
-func Login(client *PassboltClient) {
-	err := client.Client.Login(client.Context)
+// LoginError is returned by Login and wraps the error reported by go-passbolt.
+type LoginError struct {
+	Kind LoginErrorKind
+	Err  error
+}
+
+func (e *LoginError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+func (e *LoginError) Unwrap() error {
+	return e.Err
+}
+
+// NewLoginError classifies an error returned while creating the go-passbolt
+// client or logging in.
+func NewLoginError(err error) *LoginError {
+	var urlErr *url.Error
+	var netErr net.Error
+	msg := err.Error()
+
+	kind := LoginErrorUnknown
+	switch {
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		kind = LoginErrorServerUnreachable
+	case strings.Contains(msg, "Unable to Unlock UserPrivateKey"):
+		kind = LoginErrorWrongPassphrase
+	case strings.Contains(msg, "Doing Stage 1 Request"),
+		strings.Contains(msg, "Got Empty X-GPGAuth-User-Auth-Token Header"),
+		strings.Contains(msg, "Decrypting User Auth Token"):
+		kind = LoginErrorUnknownUserKey
+	case strings.Contains(msg, "PublicKey Validation Message"):
+		kind = LoginErrorServerKeyMismatch
+	}
+
+	return &LoginError{Kind: kind, Err: err}
+}
+
+// Login authenticates the client against the Passbolt server. Any failure is
+// returned as a *LoginError.
+func Login(ctx context.Context, client *PassboltClient) error {
+	ctx = client.LogContext(ctx)
+
+	if client.ServerKeyFingerprint != "" {
+		err := client.verifyServerKey(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	tflog.Debug(ctx, "Logging in to Passbolt", map[string]interface{}{"url": client.Url})
+
+	err := client.Client.Login(ctx)
 	if err != nil {
-		return
+		return NewLoginError(err)
 	}
-	fmt.Println("Logged in!")
+	registerLogin(client)
+
+	tflog.Info(ctx, "Logged in to Passbolt", map[string]interface{}{
+		"url":     client.Url,
+		"user_id": client.Client.GetUserID(),
+	})
+	return nil
 }
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestNewLoginError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want LoginErrorKind
+	}{
+		{
+			name: "connection refused",
+			err:  fmt.Errorf("Doing Stage 1 Request: %w", &url.Error{Op: "Post", URL: "https://passbolt.example.com", Err: errors.New("connection refused")}),
+			want: LoginErrorServerUnreachable,
+		},
+		{
+			name: "wrong passphrase",
+			err:  errors.New("Unable to Unlock UserPrivateKey: openpgp: invalid data: private key checksum failure"),
+			want: LoginErrorWrongPassphrase,
+		},
+		{
+			name: "unknown user key",
+			err:  errors.New("Doing Stage 1 Request: Error API JSON Response Status: Message: The user does not exist"),
+			want: LoginErrorUnknownUserKey,
+		},
+		{
+			name: "missing auth token",
+			err:  errors.New("Got Empty X-GPGAuth-User-Auth-Token Header"),
+			want: LoginErrorUnknownUserKey,
+		},
+		{
+			name: "server key mismatch",
+			err:  errors.New("PublicKey Validation Message: the server key does not match"),
+			want: LoginErrorServerKeyMismatch,
+		},
+		{
+			name: "other",
+			err:  errors.New("something else"),
+			want: LoginErrorUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewLoginError(tt.err)
+			if got.Kind != tt.want {
+				t.Errorf("NewLoginError().Kind = %v, want %v", got.Kind, tt.want)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("NewLoginError() does not wrap %v", tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrKeyNotPrivate is returned by InspectPrivateKey for public keys.
+	ErrKeyNotPrivate = errors.New("the key is not a private key")
+	// ErrKeyPassphrase is returned by InspectPrivateKey when the key cannot
+	// be unlocked with the passphrase.
+	ErrKeyPassphrase = errors.New("the key cannot be unlocked with the passphrase")
+)
+
+// KeyInfo describes a GPG key.
+type KeyInfo struct {
+	Fingerprint string
+	UserID      string
+	Algorithm   string
+	// Expires is the zero time for keys without expiry.
+	Expires time.Time
+}
+
+func (k KeyInfo) String() string {
+	expires := "never"
+	if !k.Expires.IsZero() {
+		expires = k.Expires.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("Fingerprint: %s\nUser ID: %s\nAlgorithm: %s\nExpires: %s",
+		strings.ToUpper(k.Fingerprint), k.UserID, k.Algorithm, expires)
+}
+
+// InspectPrivateKey parses an armored private key and checks that it can be
+// unlocked with passphrase. The returned KeyInfo is filled in as far as the
+// key could be parsed, also when an error is returned.
+func InspectPrivateKey(armored, passphrase string) (KeyInfo, error) {
+	var info KeyInfo
+
+	key, err := crypto.NewKeyFromArmored(armored)
+	if err != nil {
+		return info, fmt.Errorf("parsing key: %w", err)
+	}
+
+	entity := key.GetEntity()
+	info.Fingerprint = key.GetFingerprint()
+	info.Algorithm = keyAlgorithm(entity.PrimaryKey)
+
+	if identity := entity.PrimaryIdentity(); identity != nil {
+		info.UserID = identity.Name
+		if sig := identity.SelfSignature; sig != nil && sig.KeyLifetimeSecs != nil && *sig.KeyLifetimeSecs != 0 {
+			info.Expires = entity.PrimaryKey.CreationTime.Add(time.Duration(*sig.KeyLifetimeSecs) * time.Second)
+		}
+	}
+
+	if !key.IsPrivate() {
+		return info, ErrKeyNotPrivate
+	}
+
+	unlocked, err := key.Unlock([]byte(passphrase))
+	if err != nil {
+		return info, fmt.Errorf("%w: %v", ErrKeyPassphrase, err)
+	}
+	unlocked.ClearPrivateParams()
+
+	return info, nil
+}
+
+func keyAlgorithm(key *packet.PublicKey) string {
+	var name string
+	switch key.PubKeyAlgo {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSAEncryptOnly, packet.PubKeyAlgoRSASignOnly:
+		name = "RSA"
+	case packet.PubKeyAlgoDSA:
+		name = "DSA"
+	case packet.PubKeyAlgoElGamal:
+		name = "ElGamal"
+	case packet.PubKeyAlgoECDH:
+		name = "ECDH"
+	case packet.PubKeyAlgoECDSA:
+		name = "ECDSA"
+	case packet.PubKeyAlgoEdDSA:
+		name = "EdDSA"
+	case packet.PubKeyAlgoEd25519:
+		name = "Ed25519"
+	case packet.PubKeyAlgoEd448:
+		name = "Ed448"
+	default:
+		return fmt.Sprintf("unknown (%d)", key.PubKeyAlgo)
+	}
+
+	if bits, err := key.BitLength(); err == nil {
+		return fmt.Sprintf("%s %d", name, bits)
+	}
+	return name
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Profile is a named set of credentials read from the profiles file. The
+// keys follow the configuration of go-passbolt-cli.
+type Profile struct {
+	ServerAddress      string `toml:"serverAddress"`
+	UserPrivateKey     string `toml:"userPrivateKey"`
+	UserPrivateKeyFile string `toml:"userPrivateKeyFile"`
+	UserPassword       string `toml:"userPassword"`
+	TOTPToken          string `toml:"totpToken"`
+}
+
+// DefaultProfilesFile returns the location of the profiles file used when
+// none is configured.
+func DefaultProfilesFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "terraform-provider-passbolt", "profiles.toml"), nil
+}
+
+// LoadProfile reads the profile name from the TOML file at path, in which
+// every profile is a table:
+//
+//	[ci-bot]
+//	serverAddress = "https://passbolt.example.com"
+//	userPrivateKeyFile = "/etc/passbolt/ci-bot.asc"
+//	userPassword = "..."
+func LoadProfile(path, name string) (Profile, error) {
+	var profiles map[string]Profile
+
+	meta, err := toml.DecodeFile(path, &profiles)
+	if err != nil {
+		return Profile{}, fmt.Errorf("reading profiles file %s: %w", path, err)
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return Profile{}, fmt.Errorf("unknown keys in profiles file %s: %s", path, strings.Join(keys, ", "))
+	}
+
+	profile, ok := profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for n := range profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Profile{}, fmt.Errorf("profile %q not found in %s, available profiles: %s", name, path, strings.Join(names, ", "))
+	}
+
+	if profile.UserPrivateKey != "" && profile.UserPrivateKeyFile != "" {
+		return Profile{}, errors.New("profile " + name + " sets both userPrivateKey and userPrivateKeyFile")
+	}
+
+	return profile, nil
+}
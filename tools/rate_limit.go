@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"golang.org/x/time/rate"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+)
+
+// RateLimitConfig limits the load put on the Passbolt server. A zero value
+// disables the respective limit.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained request rate, bursts are allowed up
+	// to the next whole number of requests.
+	RequestsPerSecond float64
+	// MaxConcurrentRequests caps the number of requests in flight.
+	MaxConcurrentRequests int
+}
+
+// rateLimitTransport enforces a RateLimitConfig. As the HTTP client is
+// created once per provider configuration, the limits are shared by all
+// resources and data sources using it.
+type rateLimitTransport struct {
+	limiter   *rate.Limiter
+	semaphore chan struct{}
+	next      http.RoundTripper
+}
+
+func newRateLimitTransport(config RateLimitConfig, next http.RoundTripper) *rateLimitTransport {
+	t := &rateLimitTransport{next: next}
+	if config.RequestsPerSecond > 0 {
+		burst := int(math.Ceil(config.RequestsPerSecond))
+		t.limiter = rate.NewLimiter(rate.Limit(config.RequestsPerSecond), burst)
+	}
+	if config.MaxConcurrentRequests > 0 {
+		t.semaphore = make(chan struct{}, config.MaxConcurrentRequests)
+	}
+	return t
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if t.semaphore != nil {
+		select {
+		case t.semaphore <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if t.limiter != nil {
+		if err := t.limiter.Wait(ctx); err != nil {
+			t.release()
+			return nil, err
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.release()
+		return nil, err
+	}
+
+	// The request is in flight until its body has been read and closed.
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: t.release}
+	return resp, nil
+}
+
+func (t *rateLimitTransport) release() {
+	if t.semaphore != nil {
+		<-t.semaphore
+	}
+}
+
+// releasingBody calls release once when the body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
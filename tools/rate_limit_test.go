@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// okTransport answers every request with an empty 200 response, or with err
+// when set, counting the requests it receives.
+type okTransport struct {
+	err      error
+	requests int
+}
+
+func (t *okTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	t.requests++
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+// roundTripWithin sends a request through transport, giving up after wait.
+func roundTripWithin(transport http.RoundTripper, wait time.Duration) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "https://passbolt.example.com/resources.json", nil).WithContext(ctx)
+	return transport.RoundTrip(req)
+}
+
+func TestRateLimitTransportConcurrency(t *testing.T) {
+	next := &okTransport{}
+	transport := newRateLimitTransport(RateLimitConfig{MaxConcurrentRequests: 1}, next)
+
+	resp, err := roundTripWithin(transport, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The slot is held until the body of the first response is closed.
+	if _, err := roundTripWithin(transport, 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RoundTrip() with a response in flight error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if next.requests != 1 {
+		t.Fatalf("RoundTrip() sent %d requests with a response in flight, want 1", next.requests)
+	}
+
+	// Closing twice releases the slot once.
+	_ = resp.Body.Close()
+	_ = resp.Body.Close()
+	if len(transport.semaphore) != 0 {
+		t.Fatalf("RoundTrip() holds %d slots after the body was closed, want 0", len(transport.semaphore))
+	}
+
+	resp, err = roundTripWithin(transport, time.Second)
+	if err != nil {
+		t.Fatalf("RoundTrip() after the body was closed error = %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestRateLimitTransportReleasesOnError(t *testing.T) {
+	errReset := errors.New("connection reset by peer")
+	next := &okTransport{err: errReset}
+	transport := newRateLimitTransport(RateLimitConfig{MaxConcurrentRequests: 1}, next)
+
+	for i := 0; i < 3; i++ {
+		if _, err := roundTripWithin(transport, time.Second); !errors.Is(err, errReset) {
+			t.Fatalf("RoundTrip() %d error = %v, want %v", i+1, err, errReset)
+		}
+	}
+	if len(transport.semaphore) != 0 {
+		t.Errorf("RoundTrip() holds %d slots after failed requests, want 0", len(transport.semaphore))
+	}
+}
+
+func TestRateLimitTransportCancelledWait(t *testing.T) {
+	next := &okTransport{}
+	// A single token, replenished after about 17 minutes.
+	transport := newRateLimitTransport(RateLimitConfig{RequestsPerSecond: 0.001, MaxConcurrentRequests: 1}, next)
+
+	resp, err := roundTripWithin(transport, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	// Without a deadline the transport waits for the token until the
+	// context is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	req := httptest.NewRequest(http.MethodGet, "https://passbolt.example.com/resources.json", nil).WithContext(ctx)
+
+	start := time.Now()
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("RoundTrip() without a token error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("RoundTrip() waited %v for a token after the context was done", elapsed)
+	}
+	if next.requests != 1 {
+		t.Errorf("RoundTrip() sent %d requests, want 1", next.requests)
+	}
+	if len(transport.semaphore) != 0 {
+		t.Errorf("RoundTrip() holds %d slots after giving up, want 0", len(transport.semaphore))
+	}
+}
@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxRetries   = 3
+	DefaultRetryMaxWait = 30 * time.Second
+
+	retryBaseWait = 500 * time.Millisecond
+)
+
+// safePostPaths are POST endpoints without side effects which may be
+// retried like idempotent requests.
+var safePostPaths = []string{
+	"/auth/login.json",
+	"/auth/verify.json",
+	"/share/simulate/",
+}
+
+// unsafePutPaths are PUT endpoints applying permission or membership changes
+// relative to the state when they are processed, which must not be repeated
+// once the server may have processed them.
+var unsafePutPaths = []string{
+	"/share/resource/",
+	"/share/folder/",
+	"/groups/",
+}
+
+// RetryConfig controls how transient failures are retried.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// MaxWait caps the delay between two attempts, including delays
+	// requested by the server through Retry-After.
+	MaxWait time.Duration
+}
+
+// retryTransport retries requests failing with connection errors, 429 or
+// 5xx responses using exponential backoff with jitter. Requests which are not
+// idempotent are only retried on 429, as the server did not process them.
+type retryTransport struct {
+	config    RetryConfig
+	logFields map[string]interface{}
+	next      http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	idempotent := isIdempotent(req)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(req)
+
+		retry := false
+		switch {
+		case err != nil:
+			retry = idempotent && ctx.Err() == nil
+		case resp.StatusCode == http.StatusTooManyRequests:
+			retry = true
+		case resp.StatusCode >= 500:
+			retry = idempotent && isRetryableStatus(resp.StatusCode)
+		}
+
+		if !retry || attempt >= t.config.MaxRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+
+		fields := map[string]interface{}{
+			"method":  req.Method,
+			"path":    req.URL.Path,
+			"attempt": attempt + 1,
+			"wait":    wait.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = resp.StatusCode
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		tflog.Warn(ctx, "Retrying Passbolt request", t.logFields, fields)
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt, honoring Retry-After.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(wait, t.config.MaxWait)
+		}
+	}
+
+	wait := retryBaseWait << attempt
+	if wait <= 0 || wait > t.config.MaxWait {
+		wait = t.config.MaxWait
+	}
+	// Jitter between half and the full delay to spread out parallel retries.
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+		return true
+	case http.MethodPut:
+		for _, path := range unsafePutPaths {
+			if strings.Contains(req.URL.Path, path) {
+				return false
+			}
+		}
+		return true
+	case http.MethodPost:
+		for _, path := range safePostPaths {
+			if strings.Contains(req.URL.Path, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: ""},
+		{value: "0", want: 0, wantOK: true},
+		{value: "120", want: 2 * time.Minute, wantOK: true},
+		{value: "-1"},
+		{value: "soon"},
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := retryAfter(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	t.Run("future date", func(t *testing.T) {
+		got, ok := retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		if !ok || got <= 58*time.Minute || got > time.Hour {
+			t.Errorf("retryAfter() = %v, %v, want about an hour", got, ok)
+		}
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	transport := &retryTransport{config: RetryConfig{MaxWait: 4 * time.Second}}
+
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		min, max   time.Duration
+	}{
+		{name: "first attempt", attempt: 0, min: 250 * time.Millisecond, max: 500 * time.Millisecond},
+		{name: "third attempt", attempt: 2, min: time.Second, max: 2 * time.Second},
+		{name: "capped", attempt: 10, min: 2 * time.Second, max: 4 * time.Second},
+		{name: "overflow", attempt: 100, min: 2 * time.Second, max: 4 * time.Second},
+		{name: "retry after", attempt: 0, retryAfter: "3", min: 3 * time.Second, max: 3 * time.Second},
+		{name: "retry after capped", attempt: 0, retryAfter: "60", min: 4 * time.Second, max: 4 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			for i := 0; i < 20; i++ {
+				if got := transport.backoff(tt.attempt, resp); got < tt.min || got > tt.max {
+					t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{method: http.MethodGet, path: "/resources.json", want: true},
+		{method: http.MethodPut, path: "/resources/r1.json", want: true},
+		{method: http.MethodPut, path: "/share/resource/r1.json", want: false},
+		{method: http.MethodPut, path: "/share/folder/f1.json", want: false},
+		{method: http.MethodPut, path: "/groups/g1.json", want: false},
+		{method: http.MethodPut, path: "/groups/g1/dry-run.json", want: false},
+		{method: http.MethodDelete, path: "/resources/r1.json", want: true},
+		{method: http.MethodPost, path: "/resources.json", want: false},
+		{method: http.MethodPost, path: "/auth/login.json", want: true},
+		{method: http.MethodPost, path: "/share/simulate/resource/r1.json", want: true},
+		{method: http.MethodPatch, path: "/resources/r1.json", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "https://passbolt.example.com"+tt.path, nil)
+			if got := isIdempotent(req); got != tt.want {
+				t.Errorf("isIdempotent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// roundTripFunc answers requests with the responses or errors of its
+// attempts in turn.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransport(t *testing.T) {
+	errReset := errors.New("connection reset by peer")
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		attempts     []int
+		wantAttempts int
+		wantStatus   int
+		wantErr      bool
+	}{
+		{name: "success", method: http.MethodGet, attempts: []int{200}, wantAttempts: 1, wantStatus: 200},
+		{name: "server error retried", method: http.MethodGet, attempts: []int{503, 502, 200}, wantAttempts: 3, wantStatus: 200},
+		{name: "retries exhausted", method: http.MethodGet, attempts: []int{500, 500, 500, 500, 200}, wantAttempts: 4, wantStatus: 500},
+		{name: "client error not retried", method: http.MethodGet, attempts: []int{404, 200}, wantAttempts: 1, wantStatus: 404},
+		{name: "not implemented not retried", method: http.MethodGet, attempts: []int{501, 200}, wantAttempts: 1, wantStatus: 501},
+		{name: "post server error not retried", method: http.MethodPost, attempts: []int{503, 200}, wantAttempts: 1, wantStatus: 503},
+		{name: "post rate limit retried", method: http.MethodPost, attempts: []int{429, 200}, wantAttempts: 2, wantStatus: 200},
+		{name: "connection error retried", method: http.MethodDelete, attempts: []int{0, 200}, wantAttempts: 2, wantStatus: 200},
+		{name: "post connection error not retried", method: http.MethodPost, attempts: []int{0, 200}, wantAttempts: 1, wantErr: true},
+		{name: "put server error retried", method: http.MethodPut, attempts: []int{503, 200}, wantAttempts: 2, wantStatus: 200},
+		{name: "share server error not retried", method: http.MethodPut, path: "/share/resource/r1.json", attempts: []int{503, 200}, wantAttempts: 1, wantStatus: 503},
+		{name: "share connection error not retried", method: http.MethodPut, path: "/share/resource/r1.json", attempts: []int{0, 200}, wantAttempts: 1, wantErr: true},
+		{name: "group rate limit retried", method: http.MethodPut, path: "/groups/g1.json", attempts: []int{429, 200}, wantAttempts: 2, wantStatus: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			attempt := 0
+			transport := &retryTransport{
+				config: RetryConfig{MaxRetries: 3, MaxWait: time.Second},
+				next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					body, _ := io.ReadAll(req.Body)
+					bodies = append(bodies, string(body))
+
+					status := tt.attempts[attempt]
+					attempt++
+					if status == 0 {
+						return nil, errReset
+					}
+					// Retry immediately to keep the test fast.
+					header := http.Header{"Retry-After": []string{"0"}}
+					return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+				}),
+			}
+
+			path := tt.path
+			if path == "" {
+				path = "/resources.json"
+			}
+			req := httptest.NewRequest(tt.method, "https://passbolt.example.com"+path, strings.NewReader("payload"))
+			req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("payload")), nil }
+
+			resp, err := transport.RoundTrip(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RoundTrip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && resp.StatusCode != tt.wantStatus {
+				t.Errorf("RoundTrip() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if attempt != tt.wantAttempts {
+				t.Errorf("RoundTrip() made %d attempts, want %d", attempt, tt.wantAttempts)
+			}
+			for i, body := range bodies {
+				if body != "payload" {
+					t.Errorf("attempt %d sent body %q, want %q", i+1, body, "payload")
+				}
+			}
+		})
+	}
+}
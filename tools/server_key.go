@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
+)
+
+// NormalizeFingerprint removes the separators commonly used when printing
+// fingerprints and converts it to upper case.
+func NormalizeFingerprint(fingerprint string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", ":", "", "\t", "").Replace(fingerprint))
+}
+
+// verifyServerKey checks that the key published by the server matches the
+// pinned fingerprint and that the server is able to decrypt a challenge
+// encrypted to that key, proving it holds the private key.
+func (c *PassboltClient) verifyServerKey(ctx context.Context) error {
+	expected := NormalizeFingerprint(c.ServerKeyFingerprint)
+
+	armored, _, err := c.Client.GetPublicKey(ctx)
+	if err != nil {
+		return NewLoginError(fmt.Errorf("getting server key: %w", err))
+	}
+
+	key, err := crypto.NewKeyFromArmored(armored)
+	if err != nil {
+		return NewLoginError(fmt.Errorf("parsing server key: %w", err))
+	}
+
+	actual := strings.ToUpper(key.GetFingerprint())
+	if actual != expected {
+		return &LoginError{
+			Kind: LoginErrorServerFingerprintMismatch,
+			Err:  fmt.Errorf("expected server key fingerprint %s, got %s", expected, actual),
+		}
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return NewLoginError(fmt.Errorf("generating verification token: %w", err))
+	}
+	token := "gpgauthv1.3.0|36|" + id + "|gpgauthv1.3.0"
+
+	encToken, err := c.Client.EncryptMessageWithPublicKey(armored, token)
+	if err != nil {
+		return NewLoginError(fmt.Errorf("encrypting verification token: %w", err))
+	}
+
+	err = c.Client.VerifyServer(ctx, token, encToken)
+	if err != nil {
+		return &LoginError{
+			Kind: LoginErrorServerFingerprintMismatch,
+			Err:  fmt.Errorf("the server could not prove ownership of key %s: %w", actual, err),
+		}
+	}
+
+	tflog.Debug(ctx, "Verified Passbolt server key", map[string]interface{}{"fingerprint": actual})
+	return nil
+}
@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/passbolt/go-passbolt/api"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logoutTimeout bounds the logout of every client when the provider stops.
+const logoutTimeout = 10 * time.Second
+
+var (
+	loggedInMutex sync.Mutex
+	loggedIn      []*PassboltClient
+)
+
+// sessionExpiredMessages are returned by Passbolt, or a proxy in front of
+// it, when the session cookie is no longer valid.
+var sessionExpiredMessages = []string{
+	"You need to login to access this location",
+	"Authentication is required to continue",
+	"HTTP Status Code 401",
+}
+
+// IsSessionExpired reports whether err was caused by an expired or invalid
+// Passbolt session.
+func IsSessionExpired(err error) bool {
+	if err == nil {
+		return false
+	}
+	if !errors.Is(err, api.ErrAPIResponseErrorStatusCode) && !strings.Contains(err.Error(), "HTTP Status Code 401") {
+		return false
+	}
+	for _, msg := range sessionExpiredMessages {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound reports whether err was caused by Passbolt not finding the
+// requested resource, folder, group or user.
+func IsNotFound(err error) bool {
+	return err != nil && errors.Is(err, api.ErrAPIResponseErrorStatusCode) && strings.Contains(err.Error(), "does not exist")
+}
+
+// Do runs op and, when it fails because the session expired, logs in again
+// and replays op once. Concurrent callers hitting the same expired session
+// share a single login. op must not call Do itself.
+func (c *PassboltClient) Do(ctx context.Context, op func() error) error {
+	generation, err := c.run(op)
+	if !IsSessionExpired(err) {
+		return err
+	}
+
+	ctx = c.LogContext(ctx)
+	tflog.Info(ctx, "Passbolt session expired, logging in again")
+
+	err = c.relogin(ctx, generation)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.run(op)
+	return err
+}
+
+// run runs op while holding the session for reading, as go-passbolt builds
+// requests from the session and CSRF cookies that a login rewrites.
+func (c *PassboltClient) run(op func() error) (uint64, error) {
+	c.sessionMutex.RLock()
+	defer c.sessionMutex.RUnlock()
+
+	return c.sessionGeneration, op()
+}
+
+// relogin logs in again unless another caller already did so since
+// generation was observed. It waits for the requests in flight to finish.
+func (c *PassboltClient) relogin(ctx context.Context, generation uint64) error {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+
+	if c.sessionGeneration != generation {
+		return nil
+	}
+
+	if c.ServerKeyFingerprint != "" {
+		err := c.verifyServerKey(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.Client.Login(ctx)
+	if err != nil {
+		return NewLoginError(err)
+	}
+	c.sessionGeneration++
+	return nil
+}
+
+// registerLogin records a logged in client so it can be logged out when the
+// provider stops.
+func registerLogin(client *PassboltClient) {
+	loggedInMutex.Lock()
+	defer loggedInMutex.Unlock()
+
+	for _, c := range loggedIn {
+		if c == client {
+			return
+		}
+	}
+	loggedIn = append(loggedIn, client)
+}
+
+// LogoutAll closes the Passbolt session of every client that logged in.
+func LogoutAll(ctx context.Context) {
+	loggedInMutex.Lock()
+	defer loggedInMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, logoutTimeout)
+	defer cancel()
+
+	for _, client := range loggedIn {
+		client.sessionMutex.Lock()
+		err := client.Client.Logout(ctx)
+		client.sessionMutex.Unlock()
+		if err != nil {
+			tflog.Warn(client.LogContext(ctx), "Unable to log out of Passbolt", map[string]interface{}{"url": client.Url, "error": err.Error()})
+		}
+	}
+	loggedIn = nil
+}
@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// getResources requests the resources of client, as an operation run by Do.
+func getResources(ctx context.Context, client *PassboltClient) func() error {
+	return func() error {
+		_, err := client.Client.DoCustomRequest(ctx, "GET", "/resources.json", "v2", nil, nil)
+		return err
+	}
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name         string
+		expire       bool
+		status       int
+		wantLogins   int
+		wantRequests int
+		wantErr      bool
+	}{
+		{name: "valid session", status: http.StatusOK, wantLogins: 1, wantRequests: 1},
+		{name: "expired session replayed", expire: true, status: http.StatusOK, wantLogins: 2, wantRequests: 2},
+		{name: "other error not replayed", status: http.StatusInternalServerError, wantLogins: 1, wantRequests: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := newFakePassbolt(t)
+			server.handle("GET /resources.json", func(w http.ResponseWriter, _ *http.Request) {
+				if tt.status != http.StatusOK {
+					writeAPIError(w, tt.status, "Internal error.")
+					return
+				}
+				writeAPIResponse(w, []interface{}{})
+			})
+
+			client := server.client()
+			if err := Login(ctx, client); err != nil {
+				t.Fatal(err)
+			}
+			if tt.expire {
+				server.expireSessions()
+			}
+
+			err := client.Do(ctx, getResources(ctx, client))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := server.loginCount(); got != tt.wantLogins {
+				t.Errorf("Do() logged in %d times, want %d", got, tt.wantLogins)
+			}
+			if got := server.requested("GET /resources.json"); got != tt.wantRequests {
+				t.Errorf("Do() ran the operation %d times, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestDoReloginFailure(t *testing.T) {
+	ctx := context.Background()
+	server := newFakePassbolt(t)
+	server.handle("GET /resources.json", func(w http.ResponseWriter, _ *http.Request) {
+		writeAPIResponse(w, []interface{}{})
+	})
+
+	client := server.client()
+	if err := Login(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	server.expireSessions()
+	server.rejectLogins()
+
+	err := client.Do(ctx, getResources(ctx, client))
+	var loginErr *LoginError
+	if !errors.As(err, &loginErr) || loginErr.Kind != LoginErrorUnknownUserKey {
+		t.Fatalf("Do() error = %v, want a LoginError for an unknown user key", err)
+	}
+	if got := server.requested("GET /resources.json"); got != 1 {
+		t.Errorf("Do() ran the operation %d times, want 1", got)
+	}
+}
+
+func TestDoConcurrentExpiry(t *testing.T) {
+	const callers = 5
+
+	ctx := context.Background()
+	server := newFakePassbolt(t)
+	client := server.client()
+	if err := Login(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	server.expireSessions()
+
+	// Every caller is answered with the expired session only once all of
+	// them are waiting for it, so they all observe the same session.
+	var arrived sync.WaitGroup
+	arrived.Add(callers)
+	var once sync.Map
+	server.handle("GET /resources.json", func(w http.ResponseWriter, _ *http.Request) {
+		writeAPIResponse(w, []interface{}{})
+	})
+	expired := func(ctx context.Context, caller int) func() error {
+		return func() error {
+			if _, replay := once.LoadOrStore(caller, true); !replay {
+				arrived.Done()
+				waitGroup(t, &arrived)
+			}
+			return getResources(ctx, client)()
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = client.Do(ctx, expired(ctx, i))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Do() of caller %d error = %v", i, err)
+		}
+	}
+	if got := server.loginCount(); got != 2 {
+		t.Errorf("callers logged in %d times, want 2", got)
+	}
+	if got := server.requested("GET /resources.json"); got != 2*callers {
+		t.Errorf("callers made %d requests, want %d", got, 2*callers)
+	}
+}
+
+// waitGroup waits for wg, failing t when it takes too long.
+func waitGroup(t *testing.T, wg *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Error("timed out waiting for the concurrent callers")
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	ctx := context.Background()
+	loggedInMutex.Lock()
+	loggedIn = nil
+	loggedInMutex.Unlock()
+
+	server := newFakePassbolt(t)
+	server.handle("GET /resources.json", func(w http.ResponseWriter, _ *http.Request) {
+		writeAPIResponse(w, []interface{}{})
+	})
+	clients := []*PassboltClient{server.client(), server.client()}
+	for _, client := range clients {
+		if err := Login(ctx, client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Logging in again does not register the client twice.
+	if err := Login(ctx, clients[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	LogoutAll(ctx)
+	if got := server.requested("GET /auth/logout.json"); got != len(clients) {
+		t.Errorf("LogoutAll() logged out %d times, want %d", got, len(clients))
+	}
+	for _, client := range clients {
+		if err := getResources(ctx, client)(); !IsSessionExpired(err) {
+			t.Errorf("request after LogoutAll() error = %v, want an expired session", err)
+		}
+	}
+
+	LogoutAll(ctx)
+	if got := server.requested("GET /auth/logout.json"); got != len(clients) {
+		t.Errorf("second LogoutAll() logged out again, %d logouts", got)
+	}
+}